@@ -1,5 +1,7 @@
 package foxyproxy
 
+import "context"
+
 // Account represents a customer who can be assigned to one or more nodes (vpn/proxy servers).
 // See https://reseller.api.foxyproxy.com/#_accounts.
 type Account struct {
@@ -8,11 +10,11 @@ type Account struct {
 	UID      string `json:"uid"`
 	Username string `json:"username"`
 
-	client *Client
+	client API
 }
 
 // NewAccount generates a new account object.
-func NewAccount(c *Client) *Account {
+func NewAccount(c API) *Account {
 	return &Account{
 		client: c,
 	}
@@ -31,7 +33,28 @@ func (a *Account) GetNodeNames() []string {
 // Deactivate deactivates the account on it's node and returns a count of affected accounts.
 // See https://reseller.api.foxyproxy.com/#_deactivate_accounts.
 func (a *Account) Deactivate() (int, error) {
-	return a.client.deactivateAccount(a.Username, &CommonProperties{
+	return a.DeactivateContext(context.Background())
+}
+
+// DeactivateContext is like Deactivate but uses ctx for the lifetime of the request.
+func (a *Account) DeactivateContext(ctx context.Context) (int, error) {
+	return a.client.DeactivateAccountWithParamsContext(ctx, a.Username, &CommonProperties{
+		NodeNames: a.GetNodeNames(),
+	})
+}
+
+// DeactivateWithComment is like Deactivate but records comment, e.g. the reason of the
+// deactivation, on the account.
+// See https://reseller.api.foxyproxy.com/#_deactivate_accounts.
+func (a *Account) DeactivateWithComment(comment string) (int, error) {
+	return a.DeactivateWithCommentContext(context.Background(), comment)
+}
+
+// DeactivateWithCommentContext is like DeactivateWithComment but uses ctx for the lifetime of the
+// request.
+func (a *Account) DeactivateWithCommentContext(ctx context.Context, comment string) (int, error) {
+	return a.client.DeactivateAccountWithParamsContext(ctx, a.Username, &CommonProperties{
+		Comment:   comment,
 		NodeNames: a.GetNodeNames(),
 	})
 }
@@ -39,7 +62,12 @@ func (a *Account) Deactivate() (int, error) {
 // Activate activates the account on it's node and returns a count of affected accounts.
 // See https://reseller.api.foxyproxy.com/#_activate_accounts.
 func (a *Account) Activate() (int, error) {
-	return a.client.activateAccount(a.Username, &CommonProperties{
+	return a.ActivateContext(context.Background())
+}
+
+// ActivateContext is like Activate but uses ctx for the lifetime of the request.
+func (a *Account) ActivateContext(ctx context.Context) (int, error) {
+	return a.client.ActivateAccountWithParamsContext(ctx, a.Username, &CommonProperties{
 		NodeNames: a.GetNodeNames(),
 	})
 }
@@ -47,7 +75,12 @@ func (a *Account) Activate() (int, error) {
 // UpdatePassword updates the password on it's node and returns a count of affected accounts.
 // See https://reseller.api.foxyproxy.com/#_update_passwords.
 func (a *Account) UpdatePassword(password string) (int, error) {
-	return a.client.updatePassword(a.Username, password, &CommonProperties{
+	return a.UpdatePasswordContext(context.Background(), password)
+}
+
+// UpdatePasswordContext is like UpdatePassword but uses ctx for the lifetime of the request.
+func (a *Account) UpdatePasswordContext(ctx context.Context, password string) (int, error) {
+	return a.client.UpdatePasswordWithParamsContext(ctx, a.Username, password, &CommonProperties{
 		NodeNames: a.GetNodeNames(),
 	})
 }
@@ -62,7 +95,15 @@ type DeleteAccountsParams struct {
 // also deleted on it's node. Returns a count of affected accounts.
 // See https://reseller.api.foxyproxy.com/#_delete_accounts.
 func (a *Account) Delete(includeHistory bool) (int, error) {
-	return a.client.deleteAccounts(a.Username, includeHistory, &CommonProperties{
-		NodeNames: a.GetNodeNames(),
+	return a.DeleteContext(context.Background(), includeHistory)
+}
+
+// DeleteContext is like Delete but uses ctx for the lifetime of the request.
+func (a *Account) DeleteContext(ctx context.Context, includeHistory bool) (int, error) {
+	return a.client.DeleteAccountsWithParamsContext(ctx, a.Username, &DeleteAccountsParams{
+		IncludeHistory: includeHistory,
+		CommonProperties: CommonProperties{
+			NodeNames: a.GetNodeNames(),
+		},
 	})
 }
@@ -0,0 +1,74 @@
+package foxyproxy
+
+//go:generate go run ./internal/apigen
+
+import (
+	"context"
+	"time"
+)
+
+// API is the set of operations supported by Client. Code which depends on API rather than *Client
+// can be tested with a fake (see package foxyproxymock) and decorated with Decorate. Nodes and
+// accounts retrieved through an API perform their operations through it.
+//
+// The range-over-func iterators (AllNodes, AllAccounts, ...) are only available on Client.
+type API interface {
+	GetActiveNodeConnectionsByAccount(nodeName string) ([]*NodeConnection, error)
+	GetActiveNodeConnectionsByAccountContext(ctx context.Context, nodeName string) ([]*NodeConnection, error)
+	GetActiveNodeConnectionTotals(nodeName string) (int, error)
+	GetActiveNodeConnectionTotalsContext(ctx context.Context, nodeName string) (int, error)
+	GetAllNodes(index, size int) ([]*Node, error)
+	GetAllNodesContext(ctx context.Context, index, size int) ([]*Node, error)
+	GetHistoricalNodeConnectionsByAccount(nodeName string, startTime, endTime time.Time) ([]*NodeConnection, error)
+	GetHistoricalNodeConnectionsByAccountContext(ctx context.Context, nodeName string, startTime, endTime time.Time) ([]*NodeConnection, error)
+	GetHistoricalNodeConnectionTotals(nodeName string, startTime, endTime time.Time) (int, error)
+	GetHistoricalNodeConnectionTotalsContext(ctx context.Context, nodeName string, startTime, endTime time.Time) (int, error)
+	GetNode(nodeName string) (*Node, error)
+	GetNodeContext(ctx context.Context, nodeName string) (*Node, error)
+	GetNodeCount() (int, error)
+	GetNodeCountContext(ctx context.Context) (int, error)
+	GetDNSSuffixes() ([]string, error)
+	GetDNSSuffixesContext(ctx context.Context) ([]string, error)
+	GetNodeTrafficByAccount(nodeName string, startTime, endTime time.Time) ([]*NodeTrafficAccount, error)
+	GetNodeTrafficByAccountContext(ctx context.Context, nodeName string, startTime, endTime time.Time) ([]*NodeTrafficAccount, error)
+	GetNodeTrafficTotals(nodeName string, startTime, endTime time.Time) (*NodeTrafficTotals, error)
+	GetNodeTrafficTotalsContext(ctx context.Context, nodeName string, startTime, endTime time.Time) (*NodeTrafficTotals, error)
+	GetAccounts(index, size int) ([]*Account, error)
+	GetAccountsContext(ctx context.Context, index, size int) ([]*Account, error)
+	GetAccountsByUsername(username string, index, size int) ([]*Account, error)
+	GetAccountsByUsernameContext(ctx context.Context, username string, index, size int) ([]*Account, error)
+	GetAccountsByNode(nodeName string, index, size int) ([]*Account, error)
+	GetAccountsByNodeContext(ctx context.Context, nodeName string, index, size int) ([]*Account, error)
+	CountAccounts() (int, error)
+	CountAccountsContext(ctx context.Context) (int, error)
+	DeactivateAccount(username string) (int, error)
+	DeactivateAccountContext(ctx context.Context, username string) (int, error)
+	DeactivateAccountWithParams(username string, params *CommonProperties) (int, error)
+	DeactivateAccountWithParamsContext(ctx context.Context, username string, params *CommonProperties) (int, error)
+	ActivateAccount(username string) (int, error)
+	ActivateAccountContext(ctx context.Context, username string) (int, error)
+	ActivateAccountWithParams(username string, params *CommonProperties) (int, error)
+	ActivateAccountWithParamsContext(ctx context.Context, username string, params *CommonProperties) (int, error)
+	UpdatePassword(username, password string) (int, error)
+	UpdatePasswordContext(ctx context.Context, username, password string) (int, error)
+	UpdatePasswordWithParams(username, password string, params *CommonProperties) (int, error)
+	UpdatePasswordWithParamsContext(ctx context.Context, username, password string, params *CommonProperties) (int, error)
+	DeleteAccounts(username string, includeHistory bool) (int, error)
+	DeleteAccountsContext(ctx context.Context, username string, includeHistory bool) (int, error)
+	DeleteAccountsWithParams(username string, params *DeleteAccountsParams) (int, error)
+	DeleteAccountsWithParamsContext(ctx context.Context, username string, params *DeleteAccountsParams) (int, error)
+	CopyAccounts(fromNode string, toNodes []string) (int, error)
+	CopyAccountsContext(ctx context.Context, fromNode string, toNodes []string) (int, error)
+	CreateAccount(username, password string) ([]*Account, error)
+	CreateAccountContext(ctx context.Context, username, password string) ([]*Account, error)
+	CreateAccounts(username, password string, params *CommonProperties) ([]*Account, error)
+	CreateAccountsContext(ctx context.Context, username, password string, params *CommonProperties) ([]*Account, error)
+	UsernameExists(username string) (bool, error)
+	UsernameExistsContext(ctx context.Context, username string) (bool, error)
+	Nodes(ctx context.Context, params *IteratorParams) *NodeIterator
+	Accounts(ctx context.Context, params *IteratorParams) *AccountIterator
+	AccountsByUsername(ctx context.Context, username string, params *IteratorParams) *AccountIterator
+	AccountsByNode(ctx context.Context, nodeName string, params *IteratorParams) *AccountIterator
+}
+
+var _ API = (*Client)(nil)
@@ -0,0 +1,247 @@
+package foxyproxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ByteSize is an amount of traffic, in bytes. It decodes from the numeric values of the api and
+// from strings such as "50GB" (see ParseByteSize).
+//
+// The api documentation doesn't state the unit of its traffic values. They are taken to be bytes
+// and rounded to whole bytes; the values as returned are kept in the Raw field of
+// NodeTrafficTotals and NodeTrafficAccount.
+type ByteSize int64
+
+// Decimal (SI) and binary (IEC) byte size units.
+const (
+	Byte ByteSize = 1
+
+	KB ByteSize = 1000 * Byte
+	MB ByteSize = 1000 * KB
+	GB ByteSize = 1000 * MB
+	TB ByteSize = 1000 * GB
+	PB ByteSize = 1000 * TB
+	EB ByteSize = 1000 * PB
+
+	KiB ByteSize = 1024 * Byte
+	MiB ByteSize = 1024 * KiB
+	GiB ByteSize = 1024 * MiB
+	TiB ByteSize = 1024 * GiB
+	PiB ByteSize = 1024 * TiB
+	EiB ByteSize = 1024 * PiB
+)
+
+var byteSizeUnits = map[string]ByteSize{
+	"":    Byte,
+	"b":   Byte,
+	"k":   KB,
+	"kb":  KB,
+	"m":   MB,
+	"mb":  MB,
+	"g":   GB,
+	"gb":  GB,
+	"t":   TB,
+	"tb":  TB,
+	"p":   PB,
+	"pb":  PB,
+	"e":   EB,
+	"eb":  EB,
+	"kib": KiB,
+	"mib": MiB,
+	"gib": GiB,
+	"tib": TiB,
+	"pib": PiB,
+	"eib": EiB,
+}
+
+// ParseByteSize parses a byte size such as "50GB", "1.5 GiB" or "1024". Units are case-insensitive;
+// KB, MB, GB, TB, PB and EB (or K, M, G, T, P and E) are decimal, KiB, MiB, GiB, TiB, PiB and EiB
+// are binary.
+func ParseByteSize(s string) (ByteSize, error) {
+	trimmed := strings.TrimSpace(s)
+	i := strings.IndexFunc(trimmed, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.' && r != '-' && r != '+'
+	})
+	if i < 0 {
+		i = len(trimmed)
+	}
+	number, unit := trimmed[:i], strings.ToLower(strings.TrimSpace(trimmed[i:]))
+	multiplier, ok := byteSizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("foxyproxy: invalid byte size %q: unknown unit %q", s, trimmed[i:])
+	}
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("foxyproxy: invalid byte size %q", s)
+	}
+	return fromFloat(v * float64(multiplier))
+}
+
+// fromFloat rounds v to a byte size, failing if it is out of range.
+func fromFloat(v float64) (ByteSize, error) {
+	if math.IsNaN(v) || v >= math.MaxInt64 || v < math.MinInt64 {
+		return 0, fmt.Errorf("foxyproxy: byte size %v out of range", v)
+	}
+	return ByteSize(math.Round(v)), nil
+}
+
+// Bytes returns the size as a number of bytes.
+func (b ByteSize) Bytes() int64 {
+	return int64(b)
+}
+
+// KB returns the size in kilobytes.
+func (b ByteSize) KB() float64 { return float64(b) / float64(KB) }
+
+// MB returns the size in megabytes.
+func (b ByteSize) MB() float64 { return float64(b) / float64(MB) }
+
+// GB returns the size in gigabytes.
+func (b ByteSize) GB() float64 { return float64(b) / float64(GB) }
+
+// TB returns the size in terabytes.
+func (b ByteSize) TB() float64 { return float64(b) / float64(TB) }
+
+// KiB returns the size in kibibytes.
+func (b ByteSize) KiB() float64 { return float64(b) / float64(KiB) }
+
+// MiB returns the size in mebibytes.
+func (b ByteSize) MiB() float64 { return float64(b) / float64(MiB) }
+
+// GiB returns the size in gibibytes.
+func (b ByteSize) GiB() float64 { return float64(b) / float64(GiB) }
+
+// TiB returns the size in tebibytes.
+func (b ByteSize) TiB() float64 { return float64(b) / float64(TiB) }
+
+// Add returns b+o, saturating at the minimum and maximum sizes instead of overflowing.
+func (b ByteSize) Add(o ByteSize) ByteSize {
+	sum := b + o
+	switch {
+	case o > 0 && sum < b:
+		return math.MaxInt64
+	case o < 0 && sum > b:
+		return math.MinInt64
+	}
+	return sum
+}
+
+// Sub returns b-o, saturating at the minimum and maximum sizes instead of overflowing.
+func (b ByteSize) Sub(o ByteSize) ByteSize {
+	if o == math.MinInt64 {
+		return b.Add(math.MaxInt64).Add(1)
+	}
+	return b.Add(-o)
+}
+
+// SumByteSizes returns the saturating sum of sizes.
+func SumByteSizes(sizes ...ByteSize) ByteSize {
+	var sum ByteSize
+	for _, size := range sizes {
+		sum = sum.Add(size)
+	}
+	return sum
+}
+
+// String formats the size with the largest decimal unit it holds at least one of and up to two
+// decimals, e.g. 1.5 GB.
+func (b ByteSize) String() string {
+	return b.format([]ByteSize{EB, PB, TB, GB, MB, KB}, []string{"EB", "PB", "TB", "GB", "MB", "KB"})
+}
+
+// BinaryString is like String but uses binary units, e.g. 1.5 GiB.
+func (b ByteSize) BinaryString() string {
+	return b.format([]ByteSize{EiB, PiB, TiB, GiB, MiB, KiB}, []string{"EiB", "PiB", "TiB", "GiB", "MiB", "KiB"})
+}
+
+func (b ByteSize) format(units []ByteSize, names []string) string {
+	abs := b
+	if abs < 0 {
+		abs = -abs
+	}
+	for i, unit := range units {
+		if abs >= unit {
+			s := strconv.FormatFloat(float64(b)/float64(unit), 'f', 2, 64)
+			return strings.TrimRight(strings.TrimRight(s, "0"), ".") + " " + names[i]
+		}
+	}
+	return fmt.Sprintf("%d B", int64(b))
+}
+
+// UnmarshalJSON decodes a number of bytes, rounded to the nearest byte, or a string parsed with
+// ParseByteSize.
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return b.UnmarshalText([]byte(s))
+	}
+	var v float64
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("foxyproxy: invalid byte size %s", data)
+	}
+	size, err := fromFloat(v)
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// MarshalJSON encodes the size as a number of bytes.
+func (b ByteSize) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(int64(b), 10)), nil
+}
+
+// UnmarshalText parses the size with ParseByteSize, e.g. in configuration files.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// MarshalText encodes the size as a number of bytes, so that it is decoded without loss.
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatInt(int64(b), 10)), nil
+}
+
+// RawTraffic holds traffic values as returned by the api, before their conversion to ByteSize.
+// Values which are not JSON numbers, e.g. strings parsed by ParseByteSize, are zero.
+type RawTraffic struct {
+	TrafficDown float64
+	TrafficUp   float64
+	TrafficAll  float64
+	// Quota is only returned with node traffic totals.
+	Quota float64
+}
+
+// UnmarshalJSON decodes the numeric traffic values of an api object.
+func (r *RawTraffic) UnmarshalJSON(data []byte) error {
+	var v struct {
+		TrafficDown, TrafficUp, TrafficAll, Quota json.RawMessage
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	r.TrafficDown, r.TrafficUp, r.TrafficAll, r.Quota = rawNumber(v.TrafficDown), rawNumber(v.TrafficUp), rawNumber(v.TrafficAll), rawNumber(v.Quota)
+	return nil
+}
+
+// rawNumber returns the value of a JSON number, or zero.
+func rawNumber(data json.RawMessage) float64 {
+	var v float64
+	if err := json.Unmarshal(data, &v); err != nil {
+		return 0
+	}
+	return v
+}
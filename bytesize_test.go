@@ -0,0 +1,97 @@
+package foxyproxy
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected ByteSize
+	}{
+		{"1024", 1024},
+		{"50GB", 50 * GB},
+		{"50 gb", 50 * GB},
+		{"1.5 GiB", 3 * GiB / 2},
+		{"10k", 10 * KB},
+		{"2TiB", 2 * TiB},
+		{"9EB", 9 * EB},
+		{" 7 B ", 7},
+	}
+	for _, tt := range tests {
+		size, err := ParseByteSize(tt.s)
+		if err != nil || size != tt.expected {
+			t.Errorf("%q: expected %d, got (%d, %v)", tt.s, tt.expected, size, err)
+		}
+	}
+	for _, s := range []string{"", "GB", "50XB"} {
+		if _, err := ParseByteSize(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+	if _, err := ParseByteSize("9999999999EB"); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("expected out of range error, got %v", err)
+	}
+}
+
+func TestByteSizeString(t *testing.T) {
+	tests := []struct {
+		size            ByteSize
+		decimal, binary string
+	}{
+		{0, "0 B", "0 B"},
+		{999, "999 B", "999 B"},
+		{1500, "1.5 KB", "1.46 KiB"},
+		{50 * GB, "50 GB", "46.57 GiB"},
+		{-2 * MiB, "-2.1 MB", "-2 MiB"},
+	}
+	for _, tt := range tests {
+		if s := tt.size.String(); s != tt.decimal {
+			t.Errorf("%d: expected %q, got %q", int64(tt.size), tt.decimal, s)
+		}
+		if s := tt.size.BinaryString(); s != tt.binary {
+			t.Errorf("%d: expected %q, got %q", int64(tt.size), tt.binary, s)
+		}
+	}
+	if gib := (3 * GiB / 2).GiB(); gib != 1.5 {
+		t.Errorf("expected 1.5 GiB, got %v", gib)
+	}
+}
+
+func TestByteSizeArithmetic(t *testing.T) {
+	if sum := SumByteSizes(GB, 2*GB, 500*MB); sum != 3500*MB {
+		t.Errorf("expected sum: %v, got %v", 3500*MB, sum)
+	}
+	if sum := ByteSize(math.MaxInt64 - 1).Add(10); sum != math.MaxInt64 {
+		t.Errorf("expected saturated sum, got %d", int64(sum))
+	}
+	if diff := ByteSize(math.MinInt64 + 1).Sub(10); diff != math.MinInt64 {
+		t.Errorf("expected saturated difference, got %d", int64(diff))
+	}
+	if diff := GB.Sub(math.MinInt64); diff != math.MaxInt64 {
+		t.Errorf("expected saturated difference, got %d", int64(diff))
+	}
+}
+
+func TestByteSizeJSON(t *testing.T) {
+	var totals NodeTrafficTotals
+	if err := json.Unmarshal([]byte(`{"trafficDown":1.5e9,"trafficUp":"2 GB","trafficAll":3500000000.4,"quota":null}`), &totals); err != nil {
+		t.Fatal(err)
+	}
+	if totals.TrafficDown != 1500*MB || totals.TrafficUp != 2*GB || totals.TrafficAll != 3500*MB || totals.Quota != 0 {
+		t.Errorf("unexpected totals: %+v", totals)
+	}
+	if raw := (RawTraffic{TrafficDown: 1.5e9, TrafficAll: 3500000000.4}); totals.Raw != raw {
+		t.Errorf("expected raw totals: %+v, got %+v", raw, totals.Raw)
+	}
+	b, err := json.Marshal(totals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"TrafficDown":1500000000,"TrafficUp":2000000000,"TrafficAll":3500000000,"Quota":0}` {
+		t.Errorf("unexpected json: %s", b)
+	}
+}
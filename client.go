@@ -1,18 +1,31 @@
 package foxyproxy
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
-// Client represents a FoxyPoxy API client.
+// Client represents a FoxyPoxy API client. A Client is safe for concurrent use and reuses its
+// underlying HTTP connections across calls.
 type Client struct {
-	username, password string
-	domainHeader       string
-	endpointBaseURL    string
+	credentials     CredentialsProvider
+	domainHeader    string
+	endpointBaseURL string
+	httpClient      *http.Client
+	retryPolicy     *RetryPolicy
+	readLimiter     *rateLimiter
+	mutatingLimiter *rateLimiter
+	logger          *slog.Logger
+	middlewares     []Middleware
+	doer            Doer
 }
 
 // NewClientParams represents parameters used to generate a new client.
@@ -20,16 +33,118 @@ type NewClientParams struct {
 	Username, Password string
 	DomainHeader       string
 	EndpointBaseURL    string
+
+	// Credentials, if set, provides the credentials of every request instead of Username and
+	// Password, e.g. to rotate the password without creating a new client.
+	Credentials CredentialsProvider
+
+	// HTTPClient is the HTTP client used to perform requests. If set, Transport, Timeout,
+	// TLSConfig and ProxyURL are ignored.
+	HTTPClient *http.Client
+	// Transport is the round tripper used to perform requests. If set, TLSConfig and ProxyURL are
+	// ignored. Defaults to a clone of http.DefaultTransport.
+	Transport http.RoundTripper
+	// Timeout is the time limit for each request, including reading the response body. Zero
+	// means no timeout.
+	Timeout time.Duration
+	// TLSConfig is the TLS configuration used by the default transport.
+	TLSConfig *tls.Config
+	// ProxyURL is the outbound proxy used by the default transport. If nil, the proxy is taken
+	// from the environment (see http.ProxyFromEnvironment).
+	ProxyURL *url.URL
+
+	// RetryPolicy configures automatic retries of throttled and failed requests. If nil, requests
+	// are not retried.
+	RetryPolicy *RetryPolicy
+
+	// RateLimit limits the rate of requests sent by the client, and all nodes and accounts
+	// retrieved through it. If MutatingRateLimit is set, RateLimit only applies to read requests.
+	RateLimit *RateLimit
+	// MutatingRateLimit limits the rate of requests which write/change data (create, update,
+	// delete and copy accounts).
+	MutatingRateLimit *RateLimit
+
+	// Logger, if set, receives a debug record for every request attempt with its method, path,
+	// status, latency and retries. Credentials, the X-DOMAIN header and passwords sent in request
+	// bodies are redacted.
+	Logger *slog.Logger
 }
 
-// NewClient generates a new FoxyPoxy API client.
-func NewClient(params *NewClientParams) *Client {
-	// TODO handle missing parameters
-	return &Client{
-		username:        params.Username,
-		password:        params.Password,
+// NewClient generates a new FoxyPoxy API client. It returns an error matching ErrValidation if
+// the endpoint base URL, the domain header or the credentials (Username and Password, or
+// Credentials) are missing, or if the endpoint base URL is not an absolute URL.
+func NewClient(params *NewClientParams) (*Client, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	c := &Client{
+		credentials:     params.Credentials,
 		domainHeader:    params.DomainHeader,
 		endpointBaseURL: params.EndpointBaseURL,
+		httpClient:      newHTTPClient(params),
+		retryPolicy:     params.RetryPolicy,
+		logger:          params.Logger,
+		readLimiter:     newRateLimiter(params.RateLimit),
+	}
+	if c.credentials == nil {
+		c.credentials = StaticCredentials(params.Username, params.Password)
+	}
+	c.mutatingLimiter = c.readLimiter
+	if params.MutatingRateLimit != nil {
+		c.mutatingLimiter = newRateLimiter(params.MutatingRateLimit)
+	}
+	c.doer = DoerFunc(c.send)
+	return c, nil
+}
+
+// validate checks that the parameters required to send requests are set.
+func (params *NewClientParams) validate() error {
+	if params == nil {
+		return newValidationError("missing client parameters")
+	}
+	missing := []string{}
+	for _, p := range []struct {
+		name, value string
+		optional    bool
+	}{
+		{"EndpointBaseURL", params.EndpointBaseURL, false},
+		{"DomainHeader", params.DomainHeader, false},
+		{"Username", params.Username, params.Credentials != nil},
+		{"Password", params.Password, params.Credentials != nil},
+	} {
+		if p.value == "" && !p.optional {
+			missing = append(missing, p.name)
+		}
+	}
+	if len(missing) > 0 {
+		return newValidationError("missing client parameters: " + strings.Join(missing, ", "))
+	}
+	u, err := url.Parse(params.EndpointBaseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return newValidationError(fmt.Sprintf("EndpointBaseURL %q is not an absolute URL", params.EndpointBaseURL))
+	}
+	return nil
+}
+
+func newHTTPClient(params *NewClientParams) *http.Client {
+	if params.HTTPClient != nil {
+		return params.HTTPClient
+	}
+	transport := params.Transport
+	if transport == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.MaxIdleConnsPerHost = 10
+		if params.TLSConfig != nil {
+			t.TLSClientConfig = params.TLSConfig
+		}
+		if params.ProxyURL != nil {
+			t.Proxy = http.ProxyURL(params.ProxyURL)
+		}
+		transport = t
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   params.Timeout,
 	}
 }
 
@@ -37,39 +152,48 @@ func NewClient(params *NewClientParams) *Client {
 // does not include closed connections.
 // See https://reseller.api.foxyproxy.com/#_active_node_connections_by_account.
 func (c *Client) GetActiveNodeConnectionsByAccount(nodeName string) ([]*NodeConnection, error) {
-	return c.getActiveNodeConnectionsByAccount(nodeName)
+	return c.GetActiveNodeConnectionsByAccountContext(context.Background(), nodeName)
+}
+
+// GetActiveNodeConnectionsByAccountContext is like GetActiveNodeConnectionsByAccount but uses ctx
+// for the lifetime of the request.
+func (c *Client) GetActiveNodeConnectionsByAccountContext(ctx context.Context, nodeName string) ([]*NodeConnection, error) {
+	return c.getActiveNodeConnectionsByAccount(ctx, nodeName)
 }
 
 // GetActiveNodeConnectionTotals gets a count of active connections for the specified nodeName. This
 // does not include closed connections.
 // See https://reseller.api.foxyproxy.com/#_active_node_connection_totals.
 func (c *Client) GetActiveNodeConnectionTotals(nodeName string) (int, error) {
-	return c.getActiveNodeConnectionTotals(nodeName)
+	return c.GetActiveNodeConnectionTotalsContext(context.Background(), nodeName)
+}
+
+// GetActiveNodeConnectionTotalsContext is like GetActiveNodeConnectionTotals but uses ctx for the
+// lifetime of the request.
+func (c *Client) GetActiveNodeConnectionTotalsContext(ctx context.Context, nodeName string) (int, error) {
+	return c.getActiveNodeConnectionTotals(ctx, nodeName)
 }
 
 // GetAllNodes gets at most size nodes in the reseller pool, beginning at the specified zero-based
 // index. Maximum value for size is 100. Nodes are sorted lexicographically by name.
 // See https://reseller.api.foxyproxy.com/#_get_all_nodes.
 func (c *Client) GetAllNodes(index, size int) ([]*Node, error) {
+	return c.GetAllNodesContext(context.Background(), index, size)
+}
+
+// GetAllNodesContext is like GetAllNodes but uses ctx for the lifetime of the request.
+func (c *Client) GetAllNodesContext(ctx context.Context, index, size int) ([]*Node, error) {
 	// validate input
 	if index < 0 {
-		return nil, fmt.Errorf("index cannot be less than 0")
+		return nil, newValidationError("index cannot be less than 0")
 	}
 	if size > 100 {
-		return nil, fmt.Errorf("size cannot be larger than 100")
+		return nil, newValidationError("size cannot be larger than 100")
 	}
 
 	// get nodes
-	res, err := c.doRequest(http.MethodGet, fmt.Sprintf("/nodes/?index=%d&size=%d", index, size), nil)
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
 	nodes := []*Node{}
-	if err := json.Unmarshal(bodyBytes, &nodes); err != nil {
+	if err := c.doJSON(ctx, endpointGetAllNodes.request(index, size), nil, &nodes); err != nil {
 		return nil, err
 	}
 
@@ -84,30 +208,39 @@ func (c *Client) GetAllNodes(index, size int) ([]*Node, error) {
 // startTime and endTime, inclusive. This does not include active connections.
 // See https://reseller.api.foxyproxy.com/#_historical_node_connections_by_account.
 func (c *Client) GetHistoricalNodeConnectionsByAccount(nodeName string, startTime, endTime time.Time) ([]*NodeConnection, error) {
-	return c.getHistoricalNodeConnectionsByAccount(nodeName, startTime, endTime)
+	return c.GetHistoricalNodeConnectionsByAccountContext(context.Background(), nodeName, startTime, endTime)
+}
+
+// GetHistoricalNodeConnectionsByAccountContext is like GetHistoricalNodeConnectionsByAccount but
+// uses ctx for the lifetime of the request.
+func (c *Client) GetHistoricalNodeConnectionsByAccountContext(ctx context.Context, nodeName string, startTime, endTime time.Time) ([]*NodeConnection, error) {
+	return c.getHistoricalNodeConnectionsByAccount(ctx, nodeName, startTime, endTime)
 }
 
 // GetHistoricalNodeConnectionTotals gets a count of connections for the specified nodeName between
 // startTime and endTime, inclusive. This does not include active connections.
 // See https://reseller.api.foxyproxy.com/#_historical_node_connection_totals.
 func (c *Client) GetHistoricalNodeConnectionTotals(nodeName string, startTime, endTime time.Time) (int, error) {
-	return c.getHistoricalNodeConnectionTotals(nodeName, startTime, endTime)
+	return c.GetHistoricalNodeConnectionTotalsContext(context.Background(), nodeName, startTime, endTime)
+}
+
+// GetHistoricalNodeConnectionTotalsContext is like GetHistoricalNodeConnectionTotals but uses ctx
+// for the lifetime of the request.
+func (c *Client) GetHistoricalNodeConnectionTotalsContext(ctx context.Context, nodeName string, startTime, endTime time.Time) (int, error) {
+	return c.getHistoricalNodeConnectionTotals(ctx, nodeName, startTime, endTime)
 }
 
 // GetNode gets the node with the specified nodeName in the reseller pool.
 // See https://reseller.api.foxyproxy.com/#_get_node_by_name.
 func (c *Client) GetNode(nodeName string) (*Node, error) {
-	res, err := c.doRequest(http.MethodGet, fmt.Sprintf("/nodes/%s", nodeName), nil)
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
+	return c.GetNodeContext(context.Background(), nodeName)
+}
+
+// GetNodeContext is like GetNode but uses ctx for the lifetime of the request.
+func (c *Client) GetNodeContext(ctx context.Context, nodeName string) (*Node, error) {
 	node := NewNode(c)
-	if err := json.Unmarshal(bodyBytes, node); err != nil {
-		return nil, err
+	if err := c.doJSON(ctx, endpointGetNode.request(nodeName), nil, node); err != nil {
+		return nil, notFound(err, ResourceNode, nodeName)
 	}
 	return node, nil
 }
@@ -115,60 +248,78 @@ func (c *Client) GetNode(nodeName string) (*Node, error) {
 // GetNodeCount gets the total number of nodes in the reseller pool.
 // See https://reseller.api.foxyproxy.com/#_get_node_count.
 func (c *Client) GetNodeCount() (int, error) {
+	return c.GetNodeCountContext(context.Background())
+}
+
+// GetNodeCountContext is like GetNodeCount but uses ctx for the lifetime of the request.
+func (c *Client) GetNodeCountContext(ctx context.Context) (int, error) {
 	type total struct {
 		Count int
 	}
-	res, err := c.doRequest(http.MethodGet, "/nodes/count/", nil)
-	if err != nil {
-		return 0, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return 0, err
-	}
 	t := &total{}
-	if err := json.Unmarshal(bodyBytes, t); err != nil {
+	if err := c.doJSON(ctx, endpointGetNodeCount.request(), nil, t); err != nil {
 		return 0, err
 	}
 	return t.Count, nil
 }
 
+// GetDNSSuffixes gets the DNS suffixes which, combined with a node name, form the hostnames
+// customers connect to. See Node.Hostnames.
+// See https://reseller.api.foxyproxy.com/#_get_dns_suffixes.
+func (c *Client) GetDNSSuffixes() ([]string, error) {
+	return c.GetDNSSuffixesContext(context.Background())
+}
+
+// GetDNSSuffixesContext is like GetDNSSuffixes but uses ctx for the lifetime of the request.
+func (c *Client) GetDNSSuffixesContext(ctx context.Context) ([]string, error) {
+	return c.getDNSSuffixes(ctx)
+}
+
 // GetNodeTrafficByAccount gets various traffic counts and last authentication info for all
 // accounts on the specified nodeName between startTime and endTime, inclusive.
 // See https://reseller.api.foxyproxy.com/#_node_traffic_by_account.
 func (c *Client) GetNodeTrafficByAccount(nodeName string, startTime, endTime time.Time) ([]*NodeTrafficAccount, error) {
-	return c.getNodeTrafficByAccount(nodeName, startTime, endTime)
+	return c.GetNodeTrafficByAccountContext(context.Background(), nodeName, startTime, endTime)
+}
+
+// GetNodeTrafficByAccountContext is like GetNodeTrafficByAccount but uses ctx for the lifetime of
+// the request.
+func (c *Client) GetNodeTrafficByAccountContext(ctx context.Context, nodeName string, startTime, endTime time.Time) ([]*NodeTrafficAccount, error) {
+	return c.getNodeTrafficByAccount(ctx, nodeName, startTime, endTime)
 }
 
 // GetNodeTrafficTotals gets various traffic counts for the specified node between startTime and
 // endTime, inclusive.
 // See https://reseller.api.foxyproxy.com/#_node_traffic_totals.
 func (c *Client) GetNodeTrafficTotals(nodeName string, startTime, endTime time.Time) (*NodeTrafficTotals, error) {
-	return c.getNodeTrafficTotals(nodeName, startTime, endTime)
+	return c.GetNodeTrafficTotalsContext(context.Background(), nodeName, startTime, endTime)
+}
+
+// GetNodeTrafficTotalsContext is like GetNodeTrafficTotals but uses ctx for the lifetime of the
+// request.
+func (c *Client) GetNodeTrafficTotalsContext(ctx context.Context, nodeName string, startTime, endTime time.Time) (*NodeTrafficTotals, error) {
+	return c.getNodeTrafficTotals(ctx, nodeName, startTime, endTime)
 }
 
 // GetAccounts gets at most size accounts beginning at the specified zero-based index.
 // See https://reseller.api.foxyproxy.com/#_get_accounts.
 func (c *Client) GetAccounts(index, size int) ([]*Account, error) {
+	return c.GetAccountsContext(context.Background(), index, size)
+}
+
+// GetAccountsContext is like GetAccounts but uses ctx for the lifetime of the request.
+func (c *Client) GetAccountsContext(ctx context.Context, index, size int) ([]*Account, error) {
 	// validate input
 	if index < 0 {
-		return nil, fmt.Errorf("index cannot be less than 0")
+		return nil, newValidationError("index cannot be less than 0")
 	}
 	if size > 100 {
-		return nil, fmt.Errorf("size cannot be larger than 100")
+		return nil, newValidationError("size cannot be larger than 100")
 	}
 
 	// get accounts
-	res, err := c.doRequest(http.MethodGet, fmt.Sprintf("/accounts/?index=%d&size=%d", index, size), nil)
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
 	accounts := []*Account{}
-	if err := json.Unmarshal(bodyBytes, &accounts); err != nil {
+	if err := c.doJSON(ctx, endpointGetAccounts.request(index, size), nil, &accounts); err != nil {
 		return nil, err
 	}
 
@@ -183,26 +334,29 @@ func (c *Client) GetAccounts(index, size int) ([]*Account, error) {
 // returned, beginning at the specified zero-based index.
 // See https://reseller.api.foxyproxy.com/#_get_accounts_by_username.
 func (c *Client) GetAccountsByUsername(username string, index, size int) ([]*Account, error) {
+	return c.GetAccountsByUsernameContext(context.Background(), username, index, size)
+}
+
+// GetAccountsByUsernameContext is like GetAccountsByUsername but uses ctx for the lifetime of the
+// request.
+func (c *Client) GetAccountsByUsernameContext(ctx context.Context, username string, index, size int) ([]*Account, error) {
 	// validate input
 	if index < 0 {
-		return nil, fmt.Errorf("index cannot be less than 0")
+		return nil, newValidationError("index cannot be less than 0")
 	}
 	if size > 100 {
-		return nil, fmt.Errorf("size cannot be larger than 100")
+		return nil, newValidationError("size cannot be larger than 100")
 	}
 
 	// get accounts
-	res, err := c.doRequest(http.MethodGet, fmt.Sprintf("/accounts/%s/?index=%d&size=%d", username, index, size), nil)
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
 	accounts := []*Account{}
-	if err := json.Unmarshal(bodyBytes, &accounts); err != nil {
-		return nil, err
+	if err := c.doJSON(ctx, endpointGetAccountsByUsername.request(username, index, size), nil, &accounts); err != nil {
+		return nil, notFound(err, ResourceAccount, username)
+	}
+
+	// populate client
+	for _, a := range accounts {
+		a.client = c
 	}
 	return accounts, nil
 }
@@ -211,23 +365,24 @@ func (c *Client) GetAccountsByUsername(username string, index, size int) ([]*Acc
 // returned, beginning at the specified zero-based index.
 // See https://reseller.api.foxyproxy.com/#_get_accounts_by_node.
 func (c *Client) GetAccountsByNode(nodeName string, index, size int) ([]*Account, error) {
-	return c.getAccountsByNode(nodeName, index, size)
+	return c.GetAccountsByNodeContext(context.Background(), nodeName, index, size)
+}
+
+// GetAccountsByNodeContext is like GetAccountsByNode but uses ctx for the lifetime of the request.
+func (c *Client) GetAccountsByNodeContext(ctx context.Context, nodeName string, index, size int) ([]*Account, error) {
+	return c.getAccountsByNode(ctx, nodeName, index, size)
 }
 
 // CountAccounts gets the total count of accounts.
 // See https://reseller.api.foxyproxy.com/#_count_accounts.
 func (c *Client) CountAccounts() (int, error) {
+	return c.CountAccountsContext(context.Background())
+}
 
-	res, err := c.doRequest(http.MethodGet, "/accounts/count/", nil)
-	if err != nil {
-		return 0, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return 0, err
-	}
+// CountAccountsContext is like CountAccounts but uses ctx for the lifetime of the request.
+func (c *Client) CountAccountsContext(ctx context.Context) (int, error) {
 	resJSON := countResponse{}
-	if err := json.Unmarshal(bodyBytes, &resJSON); err != nil {
+	if err := c.doJSON(ctx, endpointCountAccounts.request(), nil, &resJSON); err != nil {
 		return 0, err
 	}
 	return resJSON.Count, nil
@@ -237,33 +392,114 @@ func (c *Client) CountAccounts() (int, error) {
 // accounts.
 // See https://reseller.api.foxyproxy.com/#_deactivate_accounts.
 func (c *Client) DeactivateAccount(username string) (int, error) {
-	return c.deactivateAccount(username, nil)
+	return c.DeactivateAccountContext(context.Background(), username)
+}
+
+// DeactivateAccountContext is like DeactivateAccount but uses ctx for the lifetime of the request.
+func (c *Client) DeactivateAccountContext(ctx context.Context, username string) (int, error) {
+	return c.deactivateAccount(ctx, username, nil)
+}
+
+// DeactivateAccountWithParams deactivates accounts on the nodes listed in params.NodeNames, or on
+// all nodes if none are listed, and returns a count of affected accounts.
+// See https://reseller.api.foxyproxy.com/#_deactivate_accounts.
+func (c *Client) DeactivateAccountWithParams(username string, params *CommonProperties) (int, error) {
+	return c.DeactivateAccountWithParamsContext(context.Background(), username, params)
+}
+
+// DeactivateAccountWithParamsContext is like DeactivateAccountWithParams but uses ctx for the
+// lifetime of the request.
+func (c *Client) DeactivateAccountWithParamsContext(ctx context.Context, username string, params *CommonProperties) (int, error) {
+	return c.deactivateAccount(ctx, username, params)
 }
 
 // ActivateAccount activates accounts on one or more nodes and returns a count of affected
 // accounts.
 // See https://reseller.api.foxyproxy.com/#_activate_accounts.
 func (c *Client) ActivateAccount(username string) (int, error) {
-	return c.activateAccount(username, nil)
+	return c.ActivateAccountContext(context.Background(), username)
+}
+
+// ActivateAccountContext is like ActivateAccount but uses ctx for the lifetime of the request.
+func (c *Client) ActivateAccountContext(ctx context.Context, username string) (int, error) {
+	return c.activateAccount(ctx, username, nil)
+}
+
+// ActivateAccountWithParams activates accounts on the nodes listed in params.NodeNames, or on all
+// nodes if none are listed, and returns a count of affected accounts.
+// See https://reseller.api.foxyproxy.com/#_activate_accounts.
+func (c *Client) ActivateAccountWithParams(username string, params *CommonProperties) (int, error) {
+	return c.ActivateAccountWithParamsContext(context.Background(), username, params)
+}
+
+// ActivateAccountWithParamsContext is like ActivateAccountWithParams but uses ctx for the lifetime
+// of the request.
+func (c *Client) ActivateAccountWithParamsContext(ctx context.Context, username string, params *CommonProperties) (int, error) {
+	return c.activateAccount(ctx, username, params)
 }
 
 // UpdatePassword updates the password on one or more nodes and returns a count of affected
 // accounts.
 // See https://reseller.api.foxyproxy.com/#_update_passwords.
 func (c *Client) UpdatePassword(username, password string) (int, error) {
-	return c.updatePassword(username, password, nil)
+	return c.UpdatePasswordContext(context.Background(), username, password)
+}
+
+// UpdatePasswordContext is like UpdatePassword but uses ctx for the lifetime of the request.
+func (c *Client) UpdatePasswordContext(ctx context.Context, username, password string) (int, error) {
+	return c.updatePassword(ctx, username, password, nil)
+}
+
+// UpdatePasswordWithParams updates the password on the nodes listed in params.NodeNames, or on all
+// nodes if none are listed, and returns a count of affected accounts.
+// See https://reseller.api.foxyproxy.com/#_update_passwords.
+func (c *Client) UpdatePasswordWithParams(username, password string, params *CommonProperties) (int, error) {
+	return c.UpdatePasswordWithParamsContext(context.Background(), username, password, params)
+}
+
+// UpdatePasswordWithParamsContext is like UpdatePasswordWithParams but uses ctx for the lifetime of
+// the request.
+func (c *Client) UpdatePasswordWithParamsContext(ctx context.Context, username, password string, params *CommonProperties) (int, error) {
+	return c.updatePassword(ctx, username, password, params)
 }
 
 // DeleteAccounts deletes accounts and, optionally, account history on one or more nodes and
 // returns a count of affected accounts.
 // See https://reseller.api.foxyproxy.com/#_delete_accounts.
 func (c *Client) DeleteAccounts(username string, includeHistory bool) (int, error) {
-	return c.deleteAccounts(username, includeHistory, nil)
+	return c.DeleteAccountsContext(context.Background(), username, includeHistory)
+}
+
+// DeleteAccountsContext is like DeleteAccounts but uses ctx for the lifetime of the request.
+func (c *Client) DeleteAccountsContext(ctx context.Context, username string, includeHistory bool) (int, error) {
+	return c.deleteAccounts(ctx, username, includeHistory, nil)
+}
+
+// DeleteAccountsWithParams deletes accounts and, if params.IncludeHistory is set, account history
+// on the nodes listed in params.NodeNames, or on all nodes if none are listed. Returns a count of
+// affected accounts.
+// See https://reseller.api.foxyproxy.com/#_delete_accounts.
+func (c *Client) DeleteAccountsWithParams(username string, params *DeleteAccountsParams) (int, error) {
+	return c.DeleteAccountsWithParamsContext(context.Background(), username, params)
+}
+
+// DeleteAccountsWithParamsContext is like DeleteAccountsWithParams but uses ctx for the lifetime
+// of the request.
+func (c *Client) DeleteAccountsWithParamsContext(ctx context.Context, username string, params *DeleteAccountsParams) (int, error) {
+	if params == nil {
+		return c.deleteAccounts(ctx, username, false, nil)
+	}
+	return c.deleteAccounts(ctx, username, params.IncludeHistory, &params.CommonProperties)
 }
 
 // CopyAccounts copies all accounts on fromNode to one or more other nodes.
 // See https://reseller.api.foxyproxy.com/#_copy_accounts_from_one_node_to_others.
 func (c *Client) CopyAccounts(fromNode string, toNodes []string) (int, error) {
+	return c.CopyAccountsContext(context.Background(), fromNode, toNodes)
+}
+
+// CopyAccountsContext is like CopyAccounts but uses ctx for the lifetime of the request.
+func (c *Client) CopyAccountsContext(ctx context.Context, fromNode string, toNodes []string) (int, error) {
 	params := CommonProperties{
 		NodeNames: toNodes,
 	}
@@ -272,118 +508,109 @@ func (c *Client) CopyAccounts(fromNode string, toNodes []string) (int, error) {
 		return 0, err
 	}
 
-	res, err := c.doRequest(http.MethodPost, fmt.Sprintf("/accounts/copy-all/%s/", fromNode), body)
-	if err != nil {
-		return 0, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return 0, err
-	}
 	resJSON := countResponse{}
-	if err := json.Unmarshal(bodyBytes, &resJSON); err != nil {
-		return 0, err
+	if err := c.doJSON(ctx, endpointCopyAccounts.request(fromNode), body, &resJSON); err != nil {
+		return 0, notFound(err, ResourceNode, fromNode)
 	}
 	return resJSON.Count, nil
 }
 
+// CreateAccount creates an account with the specified username and password on all nodes in the
+// reseller pool and returns the created accounts.
+// See https://reseller.api.foxyproxy.com/#_create_accounts.
+func (c *Client) CreateAccount(username, password string) ([]*Account, error) {
+	return c.CreateAccountContext(context.Background(), username, password)
+}
+
+// CreateAccountContext is like CreateAccount but uses ctx for the lifetime of the request.
+func (c *Client) CreateAccountContext(ctx context.Context, username, password string) ([]*Account, error) {
+	return c.createAccounts(ctx, username, password, nil)
+}
+
+// CreateAccounts creates an account with the specified username and password on the nodes listed
+// in params.NodeNames, or on all nodes if none are listed, and returns the created accounts.
+// See https://reseller.api.foxyproxy.com/#_create_accounts.
+func (c *Client) CreateAccounts(username, password string, params *CommonProperties) ([]*Account, error) {
+	return c.CreateAccountsContext(context.Background(), username, password, params)
+}
+
+// CreateAccountsContext is like CreateAccounts but uses ctx for the lifetime of the request.
+func (c *Client) CreateAccountsContext(ctx context.Context, username, password string, params *CommonProperties) ([]*Account, error) {
+	return c.createAccounts(ctx, username, password, params)
+}
+
 // UsernameExists returns true if the specified username exists on any node in your reseller pool.
 // See https://reseller.api.foxyproxy.com/#_username_exists.
 func (c *Client) UsernameExists(username string) (bool, error) {
-	res, err := c.doRequest(http.MethodGet, fmt.Sprintf("/accounts/exists/%s/", username), nil)
-	if err != nil {
-		return false, err
-	}
-	switch res.StatusCode {
-	case http.StatusOK:
+	return c.UsernameExistsContext(context.Background(), username)
+}
+
+// UsernameExistsContext is like UsernameExists but uses ctx for the lifetime of the request.
+func (c *Client) UsernameExistsContext(ctx context.Context, username string) (bool, error) {
+	err := c.doJSON(ctx, endpointUsernameExists.request(username), nil, nil)
+	switch {
+	case err == nil:
 		return true, nil
-	case http.StatusNotFound:
+	case errors.Is(err, ErrNotFound):
 		return false, nil
 	default:
-		return false, fmt.Errorf("unexpected response")
+		return false, err
 	}
 }
 
-func (c *Client) getActiveNodeConnectionTotals(nodeName string) (int, error) {
+func (c *Client) getActiveNodeConnectionTotals(ctx context.Context, nodeName string) (int, error) {
 	type total struct {
 		Count int
 	}
-	res, err := c.doRequest(http.MethodGet, fmt.Sprintf("/nodes/%s/connections/", nodeName), nil)
-	if err != nil {
-		return 0, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return 0, err
-	}
 	t := &total{}
-	if err := json.Unmarshal(bodyBytes, t); err != nil {
-		return 0, err
+	if err := c.doJSON(ctx, endpointGetActiveNodeConnectionTotals.request(nodeName), nil, t); err != nil {
+		return 0, notFound(err, ResourceNode, nodeName)
 	}
 	return t.Count, nil
 }
 
-func (c *Client) getDNSSuffixes() ([]string, error) {
-	res, err := c.doRequest(http.MethodGet, "/nodes/dns-suffixes/", nil)
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) getDNSSuffixes(ctx context.Context) ([]string, error) {
 	suffixes := []string{}
-	if err := json.Unmarshal(bodyBytes, &suffixes); err != nil {
+	if err := c.doJSON(ctx, endpointGetDNSSuffixes.request(), nil, &suffixes); err != nil {
 		return nil, err
 	}
 	return suffixes, nil
 }
 
-func (c *Client) getHistoricalNodeConnectionTotals(nodeName string, startTime, endTime time.Time) (int, error) {
+func (c *Client) getHistoricalNodeConnectionTotals(ctx context.Context, nodeName string, startTime, endTime time.Time) (int, error) {
 	type total struct {
 		Count int
 	}
-	res, err := c.doRequest(http.MethodGet, fmt.Sprintf("/nodes/%s/connections/%d/%d/", nodeName, startTime.Unix(), endTime.Unix()), nil)
-	if err != nil {
-		return 0, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return 0, err
-	}
 	t := &total{}
-	if err := json.Unmarshal(bodyBytes, t); err != nil {
-		return 0, err
+	if err := c.doJSON(ctx, endpointGetHistoricalNodeConnectionTotals.request(nodeName, startTime, endTime), nil, t); err != nil {
+		return 0, notFound(err, ResourceNode, nodeName)
 	}
 	return t.Count, nil
 }
 
-func (c *Client) getAccountsByNode(nodeName string, index, size int) ([]*Account, error) {
+func (c *Client) getAccountsByNode(ctx context.Context, nodeName string, index, size int) ([]*Account, error) {
 	// validate input
 	if index < 0 {
-		return nil, fmt.Errorf("index cannot be less than 0")
+		return nil, newValidationError("index cannot be less than 0")
 	}
 	if size > 100 {
-		return nil, fmt.Errorf("size cannot be larger than 100")
+		return nil, newValidationError("size cannot be larger than 100")
 	}
 
 	// get accounts
-	res, err := c.doRequest(http.MethodGet, fmt.Sprintf("/nodes/%s/accounts/?index=%d&size=%d", nodeName, index, size), nil)
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
 	accounts := []*Account{}
-	if err := json.Unmarshal(bodyBytes, &accounts); err != nil {
-		return nil, err
+	if err := c.doJSON(ctx, endpointGetAccountsByNode.request(nodeName, index, size), nil, &accounts); err != nil {
+		return nil, notFound(err, ResourceNode, nodeName)
+	}
+
+	// populate client
+	for _, a := range accounts {
+		a.client = c
 	}
 	return accounts, nil
 }
 
-func (c *Client) deactivateAccount(username string, params *CommonProperties) (int, error) {
+func (c *Client) deactivateAccount(ctx context.Context, username string, params *CommonProperties) (int, error) {
 	body := []byte{}
 	if params != nil {
 		var err error
@@ -393,22 +620,14 @@ func (c *Client) deactivateAccount(username string, params *CommonProperties) (i
 		}
 	}
 
-	res, err := c.doRequest(http.MethodPatch, fmt.Sprintf("/accounts/deactivate/%s/", username), body)
-	if err != nil {
-		return 0, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return 0, err
-	}
 	resJSON := countResponse{}
-	if err := json.Unmarshal(bodyBytes, &resJSON); err != nil {
-		return 0, err
+	if err := c.doJSON(ctx, endpointDeactivateAccount.request(username), body, &resJSON); err != nil {
+		return 0, notFound(err, ResourceAccount, username)
 	}
 	return resJSON.Count, nil
 }
 
-func (c *Client) activateAccount(username string, params *CommonProperties) (int, error) {
+func (c *Client) activateAccount(ctx context.Context, username string, params *CommonProperties) (int, error) {
 	body := []byte{}
 	if params != nil {
 		var err error
@@ -418,63 +637,81 @@ func (c *Client) activateAccount(username string, params *CommonProperties) (int
 		}
 	}
 
-	res, err := c.doRequest(http.MethodPatch, fmt.Sprintf("/accounts/activate/%s/", username), body)
-	if err != nil {
-		return 0, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return 0, err
-	}
 	resJSON := countResponse{}
-	if err := json.Unmarshal(bodyBytes, &resJSON); err != nil {
-		return 0, err
+	if err := c.doJSON(ctx, endpointActivateAccount.request(username), body, &resJSON); err != nil {
+		return 0, notFound(err, ResourceAccount, username)
 	}
 	return resJSON.Count, nil
 }
 
-func (c *Client) updatePassword(username, password string, params *CommonProperties) (int, error) {
+func (c *Client) createAccounts(ctx context.Context, username, password string, params *CommonProperties) ([]*Account, error) {
 	// validate input
-	if len(password) < 3 {
-		return 0, fmt.Errorf("password must be more than 3 characters long")
+	if username == "" {
+		return nil, newValidationError("username cannot be empty")
 	}
-	if len(password) > 127 {
-		return 0, fmt.Errorf("password must be less than 127 characters long")
+	if err := validatePassword(password); err != nil {
+		return nil, err
 	}
 
-	type updatePasswordBody struct {
+	type createAccountsBody struct {
+		Username string `json:"username"`
 		Password string `json:"password"`
 		*CommonProperties
 	}
-	upb := updatePasswordBody{
+	cab := createAccountsBody{
+		Username:         username,
 		Password:         password,
 		CommonProperties: params,
 	}
-
-	jsonBody, err := json.Marshal(upb)
+	jsonBody, err := json.Marshal(cab)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	res, err := c.doRequest(
-		http.MethodPatch,
-		fmt.Sprintf("/accounts/update-password/%s", username),
-		jsonBody,
-	)
-	if err != nil {
+
+	accounts := []*Account{}
+	if err := c.doJSON(ctx, endpointCreateAccounts.request(), jsonBody, &accounts); err != nil {
+		return nil, err
+	}
+
+	// populate client
+	for _, a := range accounts {
+		a.client = c
+	}
+	return accounts, nil
+}
+
+func (c *Client) updatePassword(ctx context.Context, username, password string, params *CommonProperties) (int, error) {
+	// validate input
+	if err := validatePassword(password); err != nil {
 		return 0, err
 	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
+
+	type updatePasswordBody struct {
+		Password string `json:"password"`
+		*CommonProperties
+	}
+	upb := updatePasswordBody{
+		Password:         password,
+		CommonProperties: params,
+	}
+
+	jsonBody, err := json.Marshal(upb)
 	if err != nil {
 		return 0, err
 	}
 	resJSON := countResponse{}
-	if err := json.Unmarshal(bodyBytes, &resJSON); err != nil {
-		return 0, err
+	if err := c.doJSON(
+		ctx,
+		endpointUpdatePassword.request(username),
+		jsonBody,
+		&resJSON,
+	); err != nil {
+		return 0, notFound(err, ResourceAccount, username)
 	}
 	return resJSON.Count, nil
 }
 
-func (c *Client) deleteAccounts(username string, includeHistory bool, params *CommonProperties) (int, error) {
+func (c *Client) deleteAccounts(ctx context.Context, username string, includeHistory bool, params *CommonProperties) (int, error) {
 	type body struct {
 		IncludeHistory bool `json:"includeHistory"`
 		*CommonProperties
@@ -488,17 +725,19 @@ func (c *Client) deleteAccounts(username string, includeHistory bool, params *Co
 		return 0, err
 	}
 
-	res, err := c.doRequest(http.MethodDelete, fmt.Sprintf("/accounts/%s/", username), bJSON)
-	if err != nil {
-		return 0, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return 0, err
-	}
 	resJSON := countResponse{}
-	if err := json.Unmarshal(bodyBytes, &resJSON); err != nil {
-		return 0, err
+	if err := c.doJSON(ctx, endpointDeleteAccounts.request(username), bJSON, &resJSON); err != nil {
+		return 0, notFound(err, ResourceAccount, username)
 	}
 	return resJSON.Count, nil
 }
+
+func validatePassword(password string) error {
+	if len(password) < 3 {
+		return newValidationError("password must be more than 3 characters long")
+	}
+	if len(password) > 127 {
+		return newValidationError("password must be less than 127 characters long")
+	}
+	return nil
+}
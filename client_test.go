@@ -1,6 +1,17 @@
 package foxyproxy
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestNewClient(t *testing.T) {
 	const (
@@ -15,17 +26,253 @@ func TestNewClient(t *testing.T) {
 		Username:        username,
 		Password:        password,
 	}
-	c := NewClient(&ncp)
+	c, err := NewClient(&ncp)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if c.domainHeader != domainHeader {
 		t.Errorf("expected client domain header: %s, got %s", domainHeader, c.domainHeader)
 	}
 	if c.endpointBaseURL != endpointBaseURL {
 		t.Errorf("expected client endpoint base url: %s, got %s", endpointBaseURL, c.endpointBaseURL)
 	}
-	if c.username != username {
-		t.Errorf("expected client username: %s, got %s", username, c.username)
+	creds, err := c.credentials.Credentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.Username != username {
+		t.Errorf("expected client username: %s, got %s", username, creds.Username)
+	}
+	if creds.Password != password {
+		t.Errorf("expected client password: %s, got %s", password, creds.Password)
+	}
+}
+
+// newTestClient generates a new client, filling in the required parameters missing from params.
+func newTestClient(t *testing.T, params *NewClientParams) *Client {
+	t.Helper()
+	if params.EndpointBaseURL == "" {
+		params.EndpointBaseURL = "https://reseller.example-inc.api.foxyproxy.com"
+	}
+	if params.DomainHeader == "" {
+		params.DomainHeader = "example-inc"
+	}
+	if params.Username == "" {
+		params.Username = "admin"
+	}
+	if params.Password == "" {
+		params.Password = "12345"
+	}
+	c, err := NewClient(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestNewClientMissingParams(t *testing.T) {
+	tests := []struct {
+		params  *NewClientParams
+		message string
+	}{
+		{nil, "missing client parameters"},
+		{&NewClientParams{}, "missing client parameters: EndpointBaseURL, DomainHeader, Username, Password"},
+		{&NewClientParams{EndpointBaseURL: "https://example.com", DomainHeader: "example", Username: "admin"}, "missing client parameters: Password"},
+		{&NewClientParams{EndpointBaseURL: "example.com", DomainHeader: "example", Username: "admin", Password: "12345"}, `EndpointBaseURL "example.com" is not an absolute URL`},
+	}
+	for _, test := range tests {
+		c, err := NewClient(test.params)
+		if c != nil || !errors.Is(err, ErrValidation) || !strings.HasSuffix(err.Error(), test.message) {
+			t.Errorf("expected validation error %q, got %v", test.message, err)
+		}
+	}
+	if _, err := NewClient(&NewClientParams{
+		EndpointBaseURL: "https://example.com",
+		DomainHeader:    "example",
+		Credentials:     StaticCredentials("admin", "12345"),
+	}); err != nil {
+		t.Errorf("expected no error with a credentials provider, got %v", err)
+	}
+}
+
+func TestClientContextCanceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+	c := newTestClient(t, &NewClientParams{
+		EndpointBaseURL: ts.URL,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetNodeCountContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error: %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestNewClientTransport(t *testing.T) {
+	calls := 0
+	c := newTestClient(t, &NewClientParams{
+		EndpointBaseURL: "https://reseller.example-inc.api.foxyproxy.com",
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"count":7}`)),
+				Request:    r,
+			}, nil
+		}),
+		Timeout: time.Second,
+	})
+	for i := 0; i < 2; i++ {
+		count, err := c.CountAccounts()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 7 {
+			t.Errorf("expected count: %d, got %d", 7, count)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected transport calls: %d, got %d", 2, calls)
+	}
+	if c.httpClient.Timeout != time.Second {
+		t.Errorf("expected client timeout: %s, got %s", time.Second, c.httpClient.Timeout)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"status":404,"error":"Not Found","path":"` + r.URL.Path + `"}`))
+	}))
+	defer ts.Close()
+	c := newTestClient(t, &NewClientParams{EndpointBaseURL: ts.URL})
+	node := NewNode(c)
+	node.Name = "missing-node"
+	account := NewAccount(c)
+	account.Username = "missing-user"
+	start, end := time.Unix(0, 0), time.Unix(3600, 0)
+
+	tests := []struct {
+		name string
+		call func() error
+		kind ResourceKind
+		id   string
+	}{
+		{"GetActiveNodeConnectionsByAccount", func() error { _, err := c.GetActiveNodeConnectionsByAccount("missing-node"); return err }, ResourceNode, "missing-node"},
+		{"GetActiveNodeConnectionTotals", func() error { _, err := c.GetActiveNodeConnectionTotals("missing-node"); return err }, ResourceNode, "missing-node"},
+		{"GetAllNodes", func() error { _, err := c.GetAllNodes(0, 10); return err }, "", ""},
+		{"GetHistoricalNodeConnectionsByAccount", func() error {
+			_, err := c.GetHistoricalNodeConnectionsByAccount("missing-node", start, end)
+			return err
+		}, ResourceNode, "missing-node"},
+		{"GetHistoricalNodeConnectionTotals", func() error { _, err := c.GetHistoricalNodeConnectionTotals("missing-node", start, end); return err }, ResourceNode, "missing-node"},
+		{"GetNode", func() error { _, err := c.GetNode("missing-node"); return err }, ResourceNode, "missing-node"},
+		{"GetNodeCount", func() error { _, err := c.GetNodeCount(); return err }, "", ""},
+		{"GetNodeTrafficByAccount", func() error { _, err := c.GetNodeTrafficByAccount("missing-node", start, end); return err }, ResourceNode, "missing-node"},
+		{"GetNodeTrafficTotals", func() error { _, err := c.GetNodeTrafficTotals("missing-node", start, end); return err }, ResourceNode, "missing-node"},
+		{"GetAccounts", func() error { _, err := c.GetAccounts(0, 10); return err }, "", ""},
+		{"GetAccountsByUsername", func() error { _, err := c.GetAccountsByUsername("missing-user", 0, 10); return err }, ResourceAccount, "missing-user"},
+		{"GetAccountsByNode", func() error { _, err := c.GetAccountsByNode("missing-node", 0, 10); return err }, ResourceNode, "missing-node"},
+		{"CountAccounts", func() error { _, err := c.CountAccounts(); return err }, "", ""},
+		{"DeactivateAccount", func() error { _, err := c.DeactivateAccount("missing-user"); return err }, ResourceAccount, "missing-user"},
+		{"ActivateAccount", func() error { _, err := c.ActivateAccount("missing-user"); return err }, ResourceAccount, "missing-user"},
+		{"UpdatePassword", func() error { _, err := c.UpdatePassword("missing-user", "secret"); return err }, ResourceAccount, "missing-user"},
+		{"DeleteAccounts", func() error { _, err := c.DeleteAccounts("missing-user", true); return err }, ResourceAccount, "missing-user"},
+		{"CopyAccounts", func() error { _, err := c.CopyAccounts("missing-node", []string{"other-node"}); return err }, ResourceNode, "missing-node"},
+		{"Node.GetActiveConnectionsByAccount", func() error { _, err := node.GetActiveConnectionsByAccount(); return err }, ResourceNode, "missing-node"},
+		{"Node.GetActiveConnectionTotals", func() error { _, err := node.GetActiveConnectionTotals(); return err }, ResourceNode, "missing-node"},
+		{"Node.GetHistoricalConnectionsByAccount", func() error { _, err := node.GetHistoricalConnectionsByAccount(start, end); return err }, ResourceNode, "missing-node"},
+		{"Node.GetHistoricalConnectionTotals", func() error { _, err := node.GetHistoricalConnectionTotals(start, end); return err }, ResourceNode, "missing-node"},
+		{"Node.GetTrafficByAccount", func() error { _, err := node.GetTrafficByAccount(start, end); return err }, ResourceNode, "missing-node"},
+		{"Node.GetTrafficTotals", func() error { _, err := node.GetTrafficTotals(start, end); return err }, ResourceNode, "missing-node"},
+		{"Node.GetAccountsByNode", func() error { _, err := node.GetAccountsByNode(0, 10); return err }, ResourceNode, "missing-node"},
+		{"Account.Deactivate", func() error { _, err := account.Deactivate(); return err }, ResourceAccount, "missing-user"},
+		{"Account.Activate", func() error { _, err := account.Activate(); return err }, ResourceAccount, "missing-user"},
+		{"Account.UpdatePassword", func() error { _, err := account.UpdatePassword("secret"); return err }, ResourceAccount, "missing-user"},
+		{"Account.Delete", func() error { _, err := account.Delete(false); return err }, ResourceAccount, "missing-user"},
+	}
+	for _, test := range tests {
+		err := test.call()
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: expected error: %v, got %v", test.name, ErrNotFound, err)
+			continue
+		}
+		if test.kind == "" {
+			continue
+		}
+		nfErr := &NotFoundError{}
+		if !errors.As(err, &nfErr) {
+			t.Errorf("%s: expected *NotFoundError, got %T", test.name, err)
+			continue
+		}
+		if nfErr.Kind != test.kind || nfErr.ID != test.id {
+			t.Errorf("%s: expected %s %q, got %s %q", test.name, test.kind, test.id, nfErr.Kind, nfErr.ID)
+		}
+	}
+
+	exists, err := c.UsernameExists("missing-user")
+	if err != nil || exists {
+		t.Errorf("UsernameExists: expected (false, nil), got (%t, %v)", exists, err)
+	}
+}
+
+func TestCreateAccounts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/accounts/" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		body := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body["username"] != "john" || body["password"] != "secret" || body["comment"] != "trial" {
+			t.Errorf("unexpected request body: %v", body)
+		}
+		accounts := []map[string]interface{}{}
+		for _, nodeName := range body["nodeNames"].([]interface{}) {
+			accounts = append(accounts, map[string]interface{}{
+				"active":   true,
+				"username": body["username"],
+				"node":     map[string]interface{}{"name": nodeName},
+			})
+		}
+		json.NewEncoder(w).Encode(accounts)
+	}))
+	defer ts.Close()
+	c := newTestClient(t, &NewClientParams{EndpointBaseURL: ts.URL})
+
+	accounts, err := c.CreateAccounts("john", "secret", &CommonProperties{
+		Comment:   "trial",
+		NodeNames: []string{"node-1", "node-2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 2 {
+		t.Fatalf("expected accounts: %d, got %d", 2, len(accounts))
+	}
+	for i, a := range accounts {
+		if name := fmt.Sprintf("node-%d", i+1); a.Username != "john" || a.Node == nil || a.Node.Name != name {
+			t.Errorf("expected account john on %s, got %+v", name, a)
+		}
+		if a.client != c {
+			t.Error("expected account client to be populated")
+		}
+	}
+
+	// invalid input is rejected before sending the request
+	if _, err := c.CreateAccounts("", "secret", nil); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected error: %v, got %v", ErrValidation, err)
 	}
-	if c.password != password {
-		t.Errorf("expected client password: %s, got %s", password, c.password)
+	if _, err := c.CreateAccount("john", "12"); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected error: %v, got %v", ErrValidation, err)
 	}
 }
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+)
+
+var accountCommands = map[string]*command{
+	"list": {
+		summary: "List the accounts of the reseller pool, or of a node with --node",
+		run:     listAccounts,
+	},
+	"find": {
+		usage:   "USERNAME",
+		summary: "Find the accounts with a username on all nodes",
+		run:     findAccounts,
+	},
+	"exists": {
+		usage:   "USERNAME",
+		summary: "Check whether a username exists on any node",
+		run:     usernameExists,
+	},
+	"activate": {
+		usage:   "USERNAME",
+		summary: "Activate the accounts with a username",
+		run:     activateAccounts,
+	},
+	"deactivate": {
+		usage:   "USERNAME",
+		summary: "Deactivate the accounts with a username",
+		run:     deactivateAccounts,
+	},
+	"passwd": {
+		usage:   "USERNAME",
+		summary: "Update the password of the accounts with a username, read from stdin",
+		run:     updatePassword,
+	},
+	"delete": {
+		usage:   "USERNAME",
+		summary: "Delete the accounts with a username",
+		run:     deleteAccounts,
+	},
+	"copy": {
+		usage:   "FROM_NODE TO_NODE...",
+		summary: "Copy all accounts of a node to other nodes",
+		run:     copyAccounts,
+	},
+}
+
+// commonFlags defines the --node and --comment flags on fs.
+func commonFlags(fs *flag.FlagSet) func() *foxyproxy.CommonProperties {
+	nodes := fs.String("node", "", "comma-separated `names` of the nodes to limit the operation to (default all nodes)")
+	comment := fs.String("comment", "", "comment recorded on the accounts")
+	return func() *foxyproxy.CommonProperties {
+		p := &foxyproxy.CommonProperties{Comment: *comment}
+		if *nodes != "" {
+			p.NodeNames = strings.Split(*nodes, ",")
+		}
+		return p
+	}
+}
+
+func listAccounts(a *app, fs *flag.FlagSet, args []string) (*result, error) {
+	p := pageFlags(fs)
+	node := fs.String("node", "", "list the accounts of the node `name`")
+	if _, err := parse(fs, args, 0); err != nil {
+		return nil, err
+	}
+	c, err := a.client()
+	if err != nil {
+		return nil, err
+	}
+	var accounts []*foxyproxy.Account
+	switch {
+	case *p.all:
+		it := c.Accounts(a.ctx, nil)
+		if *node != "" {
+			it = c.AccountsByNode(a.ctx, *node, nil)
+		}
+		accounts, err = collectAccounts(it)
+	case *node != "":
+		accounts, err = c.GetAccountsByNodeContext(a.ctx, *node, *p.index, *p.size)
+	default:
+		accounts, err = c.GetAccountsContext(a.ctx, *p.index, *p.size)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return accountsResult(accounts), nil
+}
+
+func findAccounts(a *app, fs *flag.FlagSet, args []string) (*result, error) {
+	args, err := parse(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	c, err := a.client()
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := collectAccounts(c.AccountsByUsername(a.ctx, args[0], nil))
+	if err != nil {
+		return nil, err
+	}
+	return accountsResult(accounts), nil
+}
+
+func usernameExists(a *app, fs *flag.FlagSet, args []string) (*result, error) {
+	args, err := parse(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	c, err := a.client()
+	if err != nil {
+		return nil, err
+	}
+	exists, err := c.UsernameExistsContext(a.ctx, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return &result{
+		value:  map[string]bool{"exists": exists},
+		header: []string{"EXISTS"},
+		rows:   [][]interface{}{{exists}},
+	}, nil
+}
+
+func activateAccounts(a *app, fs *flag.FlagSet, args []string) (*result, error) {
+	common := commonFlags(fs)
+	args, err := parse(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	c, err := a.client()
+	if err != nil {
+		return nil, err
+	}
+	count, err := c.ActivateAccountWithParamsContext(a.ctx, args[0], common())
+	if err != nil {
+		return nil, err
+	}
+	return countResult(count), nil
+}
+
+func deactivateAccounts(a *app, fs *flag.FlagSet, args []string) (*result, error) {
+	common := commonFlags(fs)
+	args, err := parse(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	c, err := a.client()
+	if err != nil {
+		return nil, err
+	}
+	count, err := c.DeactivateAccountWithParamsContext(a.ctx, args[0], common())
+	if err != nil {
+		return nil, err
+	}
+	return countResult(count), nil
+}
+
+func updatePassword(a *app, fs *flag.FlagSet, args []string) (*result, error) {
+	common := commonFlags(fs)
+	password := fs.String("new-password", "", "new password (default read from stdin)")
+	args, err := parse(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	if *password == "" {
+		if *password, err = a.readLine(); err != nil {
+			return nil, err
+		}
+	}
+	c, err := a.client()
+	if err != nil {
+		return nil, err
+	}
+	count, err := c.UpdatePasswordWithParamsContext(a.ctx, args[0], *password, common())
+	if err != nil {
+		return nil, err
+	}
+	return countResult(count), nil
+}
+
+func deleteAccounts(a *app, fs *flag.FlagSet, args []string) (*result, error) {
+	common := commonFlags(fs)
+	history := fs.Bool("history", false, "also delete the history of the accounts")
+	yes := fs.Bool("yes", false, "confirm the deletion")
+	args, err := parse(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	if !*yes {
+		return nil, usageError("deleting accounts requires --yes")
+	}
+	c, err := a.client()
+	if err != nil {
+		return nil, err
+	}
+	count, err := c.DeleteAccountsWithParamsContext(a.ctx, args[0], &foxyproxy.DeleteAccountsParams{
+		IncludeHistory:   *history,
+		CommonProperties: *common(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return countResult(count), nil
+}
+
+func copyAccounts(a *app, fs *flag.FlagSet, args []string) (*result, error) {
+	args, err := parse(fs, args, -2)
+	if err != nil {
+		return nil, err
+	}
+	c, err := a.client()
+	if err != nil {
+		return nil, err
+	}
+	count, err := c.CopyAccountsContext(a.ctx, args[0], args[1:])
+	if err != nil {
+		return nil, err
+	}
+	return countResult(count), nil
+}
+
+func collectAccounts(it *foxyproxy.AccountIterator) ([]*foxyproxy.Account, error) {
+	accounts := []*foxyproxy.Account{}
+	for it.Next() {
+		accounts = append(accounts, it.Account())
+	}
+	return accounts, it.Err()
+}
+
+func accountsResult(accounts []*foxyproxy.Account) *result {
+	res := &result{
+		value:  accounts,
+		header: []string{"USERNAME", "NODE", "ACTIVE", "UID"},
+	}
+	for _, account := range accounts {
+		res.rows = append(res.rows, []interface{}{account.Username, strings.Join(account.GetNodeNames(), ","), account.Active, account.UID})
+	}
+	return res
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+)
+
+// defaultTimeout is the request timeout when neither --timeout nor the profile set one.
+const defaultTimeout = 30 * time.Second
+
+// loadConfig reads the profiles of the config file. A missing default config file is not an
+// error.
+func (a *app) loadConfig() (*foxyproxy.Config, error) {
+	path, explicit := a.config, a.config != "" || os.Getenv("FOXYPROXY_CONFIG") != ""
+	if path == "" {
+		var err error
+		if path, err = foxyproxy.DefaultConfigPath(); err != nil {
+			return &foxyproxy.Config{}, nil
+		}
+	}
+	cfg, err := foxyproxy.LoadConfig(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return &foxyproxy.Config{}, nil
+		}
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// client returns a client configured from the flags, environment and config profile, in this
+// order of precedence.
+func (a *app) client() (*foxyproxy.Client, error) {
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	params := &foxyproxy.NewClientParams{}
+	_, hasDefault := cfg.Profiles[foxyproxy.DefaultProfile]
+	if a.profile != "" || os.Getenv("FOXYPROXY_PROFILE") != "" || hasDefault {
+		if params, err = cfg.Params(a.profile); err != nil {
+			return nil, err
+		}
+	}
+	params.Username = first(a.username, os.Getenv("FOXYPROXY_USERNAME"), params.Username)
+	params.Password = first(a.password, os.Getenv("FOXYPROXY_PASSWORD"), params.Password)
+	params.DomainHeader = first(a.domain, os.Getenv("FOXYPROXY_DOMAIN"), params.DomainHeader)
+	params.EndpointBaseURL = first(a.endpoint, os.Getenv("FOXYPROXY_ENDPOINT"), params.EndpointBaseURL)
+	if a.timeout > 0 {
+		params.Timeout = a.timeout
+	} else if params.Timeout == 0 {
+		params.Timeout = defaultTimeout
+	}
+	params.RetryPolicy = &foxyproxy.DefaultRetryPolicy
+	c, err := foxyproxy.NewClient(params)
+	if err != nil {
+		return nil, fmt.Errorf("%w; set the flags, the FOXYPROXY_* environment variables or a config profile", err)
+	}
+	return c, nil
+}
+
+// first returns the first non-empty value.
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,227 @@
+// Command foxyctl runs day-to-day operations against the FoxyProxy reseller api.
+//
+// Usage:
+//
+//	foxyctl <group> <command> [flags] [arguments]
+//
+// The commands are:
+//
+//	nodes list|get|count
+//	accounts list|find|exists|activate|deactivate|passwd|delete|copy
+//	traffic totals|by-account
+//	connections active|historical
+//
+// Credentials are read from flags (--username, --password, --domain, --endpoint), then from the
+// FOXYPROXY_USERNAME, FOXYPROXY_PASSWORD, FOXYPROXY_DOMAIN and FOXYPROXY_ENDPOINT environment
+// variables, then from a profile (--profile, $FOXYPROXY_PROFILE or "default") of the config file
+// (--config, $FOXYPROXY_CONFIG, or foxyproxy/config.yaml in the user config directory). See
+// foxyproxy.Config for the format of the config file.
+//
+// Results are printed as a table by default; use --output json, csv or yaml for other formats.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// command is a foxyctl subcommand. run parses its flags and arguments with fs, which already
+// defines the global flags, and returns the result to print.
+type command struct {
+	usage   string
+	summary string
+	run     func(a *app, fs *flag.FlagSet, args []string) (*result, error)
+}
+
+var commands = map[string]map[string]*command{
+	"nodes":       nodeCommands,
+	"accounts":    accountCommands,
+	"traffic":     trafficCommands,
+	"connections": connectionCommands,
+}
+
+// app holds the environment and global options of a foxyctl invocation.
+type app struct {
+	ctx            context.Context
+	stdin          io.Reader
+	stdout, stderr io.Writer
+
+	config   string
+	profile  string
+	username string
+	password string
+	domain   string
+	endpoint string
+	output   outputFormat
+	timeout  time.Duration
+}
+
+func main() {
+	a := &app{
+		ctx:    context.Background(),
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+	os.Exit(a.run(os.Args[1:]))
+}
+
+// run runs the command line args and returns the exit code.
+func (a *app) run(args []string) int {
+	if len(args) < 2 || commands[args[0]] == nil || commands[args[0]][args[1]] == nil {
+		if len(args) > 0 && args[0] != "help" && args[0] != "-h" && args[0] != "--help" {
+			fmt.Fprintf(a.stderr, "foxyctl: unknown command %q\n\n", strings.Join(args[:min(len(args), 2)], " "))
+		}
+		a.usage()
+		return 2
+	}
+	cmd := commands[args[0]][args[1]]
+	name := args[0] + " " + args[1]
+	fs := a.flagSet(name)
+	fs.Usage = func() {
+		fmt.Fprintf(a.stderr, "Usage: foxyctl %s [flags] %s\n\n%s.\n\nFlags:\n", name, cmd.usage, cmd.summary)
+		fs.PrintDefaults()
+	}
+	res, err := cmd.run(a, fs, args[2:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err == nil {
+		err = a.print(res)
+	}
+	if err != nil {
+		fmt.Fprintf(a.stderr, "foxyctl %s: %v\n", name, err)
+		var uerr usageError
+		if errors.As(err, &uerr) {
+			fs.Usage()
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+func (a *app) usage() {
+	fmt.Fprintln(a.stderr, "Usage: foxyctl <group> <command> [flags] [arguments]")
+	fmt.Fprintln(a.stderr)
+	fmt.Fprintln(a.stderr, "Commands:")
+	groups := make([]string, 0, len(commands))
+	for group := range commands {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		names := make([]string, 0, len(commands[group]))
+		for name := range commands[group] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			cmd := commands[group][name]
+			fmt.Fprintf(a.stderr, "  %-40s %s\n", strings.TrimSpace(group+" "+name+" "+cmd.usage), cmd.summary)
+		}
+	}
+	fmt.Fprintln(a.stderr)
+	fmt.Fprintln(a.stderr, `Run "foxyctl <group> <command> --help" for the flags of a command.`)
+}
+
+// flagSet returns a flag set defining the global flags.
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.StringVar(&a.config, "config", "", "config `file` (default $FOXYPROXY_CONFIG or foxyproxy/config.yaml in the user config directory)")
+	fs.StringVar(&a.profile, "profile", "", "config profile `name` (default $FOXYPROXY_PROFILE or default)")
+	fs.StringVar(&a.username, "username", "", "api username (default $FOXYPROXY_USERNAME)")
+	fs.StringVar(&a.password, "password", "", "api password (default $FOXYPROXY_PASSWORD)")
+	fs.StringVar(&a.domain, "domain", "", "X-DOMAIN header (default $FOXYPROXY_DOMAIN)")
+	fs.StringVar(&a.endpoint, "endpoint", "", "endpoint base `url` (default $FOXYPROXY_ENDPOINT)")
+	a.output = "table"
+	fs.Var(&a.output, "output", "output `format`: table, json, csv or yaml")
+	fs.DurationVar(&a.timeout, "timeout", 0, "request timeout (default the profile's or 30s)")
+	return fs
+}
+
+// usageError is returned for invalid arguments.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// parse parses the flags and arguments of a command, which must have exactly nargs arguments, or
+// at least -nargs if nargs is negative.
+func parse(fs *flag.FlagSet, args []string, nargs int) ([]string, error) {
+	// allow flags after the arguments, e.g. foxyctl nodes get node-1 --output json
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if (nargs >= 0 && len(positional) != nargs) || (nargs < 0 && len(positional) < -nargs) {
+		return nil, usageError("wrong number of arguments")
+	}
+	return positional, nil
+}
+
+// readLine reads a line from stdin, e.g. a password.
+func (a *app) readLine() (string, error) {
+	line, err := bufio.NewReader(a.stdin).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("reading stdin: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// parseTime parses a time flag, either RFC 3339, a date or a unix timestamp.
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	var unix int64
+	if _, err := fmt.Sscanf(s, "%d", &unix); err == nil && fmt.Sprint(unix) == s {
+		return time.Unix(unix, 0), nil
+	}
+	return time.Time{}, usageError(fmt.Sprintf("invalid time %q", s))
+}
+
+// timeRange defines the --start and --end flags on fs and returns a function parsing them. They
+// default to the last 24 hours.
+func timeRange(fs *flag.FlagSet) func() (time.Time, time.Time, error) {
+	start := fs.String("start", "", "start `time` (RFC 3339, date or unix timestamp; default 24 hours before end)")
+	end := fs.String("end", "", "end `time` (RFC 3339, date or unix timestamp; default now)")
+	return func() (time.Time, time.Time, error) {
+		endTime := time.Now()
+		if *end != "" {
+			t, err := parseTime(*end)
+			if err != nil {
+				return time.Time{}, time.Time{}, err
+			}
+			endTime = t
+		}
+		startTime := endTime.Add(-24 * time.Hour)
+		if *start != "" {
+			t, err := parseTime(*start)
+			if err != nil {
+				return time.Time{}, time.Time{}, err
+			}
+			startTime = t
+		}
+		return startTime, endTime, nil
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"github.com/jsignanini/foxyproxy-reseller-go/foxyproxytest"
+)
+
+func newServer() *foxyproxytest.Server {
+	srv := foxyproxytest.NewServer()
+	srv.AddNode(&foxyproxy.Node{Name: "node-1", Active: true, Country: "US"})
+	srv.AddNode(&foxyproxy.Node{Name: "node-2", Active: true, Country: "DE"})
+	srv.AddAccount(&foxyproxytest.AccountState{NodeName: "node-1", Username: "john", Password: "secret", Active: true})
+	srv.AddAccount(&foxyproxytest.AccountState{NodeName: "node-2", Username: "john", Password: "secret", Active: true})
+	return srv
+}
+
+// run runs foxyctl with the credentials of srv in the environment.
+func run(t *testing.T, srv *foxyproxytest.Server, stdin string, args ...string) (int, string, string) {
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(config, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FOXYPROXY_CONFIG", config)
+	t.Setenv("FOXYPROXY_PROFILE", "")
+	t.Setenv("FOXYPROXY_USERNAME", srv.Username)
+	t.Setenv("FOXYPROXY_PASSWORD", srv.Password)
+	t.Setenv("FOXYPROXY_DOMAIN", srv.Domain)
+	t.Setenv("FOXYPROXY_ENDPOINT", srv.URL)
+	var stdout, stderr bytes.Buffer
+	a := &app{
+		ctx:    context.Background(),
+		stdin:  strings.NewReader(stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+	}
+	code := a.run(args)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestNodes(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	code, stdout, stderr := run(t, srv, "", "nodes", "list")
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "NAME") || !strings.HasPrefix(lines[1], "node-1") {
+		t.Errorf("unexpected table:\n%s", stdout)
+	}
+
+	code, stdout, stderr = run(t, srv, "", "nodes", "get", "node-2", "--output", "json")
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	var node foxyproxy.Node
+	if err := json.Unmarshal([]byte(stdout), &node); err != nil {
+		t.Fatal(err)
+	}
+	if node.Name != "node-2" || node.Country != "DE" {
+		t.Errorf("unexpected node: %+v", node)
+	}
+
+	code, stdout, _ = run(t, srv, "", "nodes", "count", "--output", "yaml")
+	if code != 0 || stdout != "count: 2\n" {
+		t.Errorf("unexpected output %q, exit code %d", stdout, code)
+	}
+}
+
+func TestAccounts(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	code, stdout, stderr := run(t, srv, "", "accounts", "deactivate", "john", "--node", "node-2", "--comment", "unpaid")
+	if code != 0 || strings.TrimSpace(stdout) != "COUNT\n1" {
+		t.Fatalf("unexpected output %q, exit code %d: %s", stdout, code, stderr)
+	}
+	for _, a := range srv.Accounts() {
+		if a.Active != (a.NodeName == "node-1") {
+			t.Errorf("unexpected state of %s on %s: %+v", a.Username, a.NodeName, a)
+		}
+	}
+
+	code, _, stderr = run(t, srv, "new-secret\n", "accounts", "passwd", "john")
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	for _, a := range srv.Accounts() {
+		if a.Password != "new-secret" {
+			t.Errorf("password not updated on %s: %q", a.NodeName, a.Password)
+		}
+	}
+
+	code, stdout, _ = run(t, srv, "", "accounts", "find", "john", "--output", "csv")
+	if code != 0 || strings.Count(stdout, "\n") != 3 || !strings.HasPrefix(stdout, "USERNAME,NODE,ACTIVE,UID\n") {
+		t.Errorf("unexpected output %q, exit code %d", stdout, code)
+	}
+
+	requests := srv.RequestCount()
+	if code, _, _ = run(t, srv, "", "accounts", "delete", "john"); code != 2 {
+		t.Errorf("expected exit code 2 without --yes, got %d", code)
+	}
+	if len(srv.Accounts()) != 2 {
+		t.Errorf("accounts deleted without --yes")
+	}
+	code, _, stderr = run(t, srv, "", "accounts", "delete", "john", "--yes", "--output", "xml")
+	if code == 0 || !strings.Contains(stderr, "unknown output format") {
+		t.Errorf("unexpected exit code %d: %s", code, stderr)
+	}
+	if n := srv.RequestCount(); n != requests {
+		t.Errorf("%d requests sent with an invalid --output", n-requests)
+	}
+	if len(srv.Accounts()) != 2 {
+		t.Errorf("accounts deleted with an invalid --output")
+	}
+}
+
+func TestTraffic(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+	srv.AddTraffic("node-1", "john", time.Unix(1000, 0), 1500, 2*foxyproxy.GB)
+
+	code, stdout, stderr := run(t, srv, "", "traffic", "by-account", "node-1", "--start", "0", "--end", "2000", "--output", "csv")
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, ",2000000000,1500,2000001500\n") {
+		t.Errorf("unexpected output %q", stdout)
+	}
+
+	code, stdout, _ = run(t, srv, "", "traffic", "totals", "node-1", "--start", "0", "--end", "2000")
+	if code != 0 || !strings.Contains(stdout, "2 GB") {
+		t.Errorf("unexpected output %q, exit code %d", stdout, code)
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+	for _, key := range []string{"CONFIG", "PROFILE", "USERNAME", "PASSWORD", "DOMAIN", "ENDPOINT"} {
+		t.Setenv("FOXYPROXY_"+key, "")
+	}
+
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	data := "profiles:\n  brand-a:\n    username: " + srv.Username + "\n    password: wrong\n    domain: " + srv.Domain + "\n    endpoint: " + srv.URL + "\n"
+	if err := os.WriteFile(config, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	a := &app{ctx: context.Background(), stdout: &stdout, stderr: &stderr}
+	if code := a.run([]string{"nodes", "count", "--config", config, "--profile", "brand-a"}); code != 1 {
+		t.Errorf("expected exit code 1 with the wrong password, got %d", code)
+	}
+	stdout.Reset()
+	if code := a.run([]string{"nodes", "count", "--config", config, "--profile", "brand-a", "--password", srv.Password}); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	if strings.TrimSpace(stdout.String()) != "COUNT\n2" {
+		t.Errorf("unexpected output %q", stdout.String())
+	}
+
+	stderr.Reset()
+	if code := a.run([]string{"nodes", "count", "--config", config}); code != 1 || !strings.Contains(stderr.String(), "missing client parameters") {
+		t.Errorf("expected an error for the missing parameters, got exit code %d: %s", code, stderr.String())
+	}
+
+	stderr.Reset()
+	t.Setenv("FOXYPROXY_CONFIG", filepath.Join(t.TempDir(), "missing.yaml"))
+	if code := a.run([]string{"nodes", "count"}); code != 1 || !strings.Contains(stderr.String(), "no such file") {
+		t.Errorf("expected an error for the missing config file, got exit code %d: %s", code, stderr.String())
+	}
+}
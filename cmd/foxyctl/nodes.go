@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+)
+
+var nodeCommands = map[string]*command{
+	"list": {
+		summary: "List the nodes of the reseller pool",
+		run:     listNodes,
+	},
+	"get": {
+		usage:   "NODE",
+		summary: "Get a node",
+		run:     getNode,
+	},
+	"count": {
+		summary: "Count the nodes of the reseller pool",
+		run:     countNodes,
+	},
+}
+
+// page defines the --index, --size and --all flags on fs.
+type page struct {
+	index, size *int
+	all         *bool
+}
+
+func pageFlags(fs *flag.FlagSet) *page {
+	return &page{
+		index: fs.Int("index", 0, "index of the first item"),
+		size:  fs.Int("size", 100, "number of items, up to 100"),
+		all:   fs.Bool("all", false, "page through all items, ignoring --index and --size"),
+	}
+}
+
+func listNodes(a *app, fs *flag.FlagSet, args []string) (*result, error) {
+	p := pageFlags(fs)
+	if _, err := parse(fs, args, 0); err != nil {
+		return nil, err
+	}
+	c, err := a.client()
+	if err != nil {
+		return nil, err
+	}
+	nodes := []*foxyproxy.Node{}
+	if *p.all {
+		it := c.Nodes(a.ctx, nil)
+		for it.Next() {
+			nodes = append(nodes, it.Node())
+		}
+		err = it.Err()
+	} else {
+		nodes, err = c.GetAllNodesContext(a.ctx, *p.index, *p.size)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nodesResult(nodes), nil
+}
+
+func getNode(a *app, fs *flag.FlagSet, args []string) (*result, error) {
+	args, err := parse(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	c, err := a.client()
+	if err != nil {
+		return nil, err
+	}
+	node, err := c.GetNodeContext(a.ctx, args[0])
+	if err != nil {
+		return nil, err
+	}
+	res := nodesResult([]*foxyproxy.Node{node})
+	res.value = node
+	return res, nil
+}
+
+func countNodes(a *app, fs *flag.FlagSet, args []string) (*result, error) {
+	if _, err := parse(fs, args, 0); err != nil {
+		return nil, err
+	}
+	c, err := a.client()
+	if err != nil {
+		return nil, err
+	}
+	count, err := c.GetNodeCountContext(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+	return countResult(count), nil
+}
+
+func nodesResult(nodes []*foxyproxy.Node) *result {
+	res := &result{
+		value:  nodes,
+		header: []string{"NAME", "ACTIVE", "IP ADDRESS", "COUNTRY", "CITY"},
+	}
+	for _, n := range nodes {
+		res.rows = append(res.rows, []interface{}{n.Name, n.Active, n.IPAddress, n.Country, n.City})
+	}
+	return res
+}
+
+func countResult(count int) *result {
+	return &result{
+		value:  map[string]int{"count": count},
+		header: []string{"COUNT"},
+		rows:   [][]interface{}{{count}},
+	}
+}
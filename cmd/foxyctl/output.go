@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"gopkg.in/yaml.v3"
+)
+
+// result is the result of a command. value is printed as JSON or YAML, header and rows as a table
+// or CSV.
+type result struct {
+	value  interface{}
+	header []string
+	rows   [][]interface{}
+}
+
+// outputFormat is the value of the --output flag. It is checked when the flags are parsed, so that
+// commands don't run with an output format which cannot be printed.
+type outputFormat string
+
+func (f *outputFormat) String() string {
+	return string(*f)
+}
+
+func (f *outputFormat) Set(s string) error {
+	switch s {
+	case "table", "json", "csv", "yaml":
+		*f = outputFormat(s)
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q, expected table, json, csv or yaml", s)
+	}
+}
+
+// print writes res to stdout in the output format.
+func (a *app) print(res *result) error {
+	switch a.output {
+	case "json":
+		b, err := json.MarshalIndent(res.value, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(a.stdout, "%s\n", b)
+		return err
+	case "yaml":
+		v, err := jsonValue(res.value)
+		if err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(a.stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	case "csv":
+		w := csv.NewWriter(a.stdout)
+		w.Write(res.header)
+		for _, row := range res.rows {
+			record := make([]string, len(row))
+			for i, cell := range row {
+				record[i] = csvCell(cell)
+			}
+			w.Write(record)
+		}
+		w.Flush()
+		return w.Error()
+	case "table", "":
+		w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+		for i, h := range res.header {
+			if i > 0 {
+				fmt.Fprint(w, "\t")
+			}
+			fmt.Fprint(w, h)
+		}
+		fmt.Fprintln(w)
+		for _, row := range res.rows {
+			for i, cell := range row {
+				if i > 0 {
+					fmt.Fprint(w, "\t")
+				}
+				fmt.Fprint(w, cell)
+			}
+			fmt.Fprintln(w)
+		}
+		return w.Flush()
+	default:
+		return usageError(fmt.Sprintf("unknown output format %q", a.output))
+	}
+}
+
+// csvCell formats a cell for machine consumption: byte sizes are written as a number of bytes.
+func csvCell(cell interface{}) string {
+	if size, ok := cell.(foxyproxy.ByteSize); ok {
+		return strconv.FormatInt(size.Bytes(), 10)
+	}
+	return fmt.Sprint(cell)
+}
+
+// jsonValue converts v to the generic value of its JSON encoding, so that it is printed as YAML
+// with the same keys. Integers are kept as such.
+func jsonValue(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return convertNumbers(generic), nil
+}
+
+func convertNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = convertNumbers(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = convertNumbers(value)
+		}
+	}
+	return v
+}
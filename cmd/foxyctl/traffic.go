@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+)
+
+var trafficCommands = map[string]*command{
+	"totals": {
+		usage:   "NODE",
+		summary: "Get the traffic totals of a node",
+		run:     trafficTotals,
+	},
+	"by-account": {
+		usage:   "NODE",
+		summary: "Get the traffic of each account of a node",
+		run:     trafficByAccount,
+	},
+}
+
+var connectionCommands = map[string]*command{
+	"active": {
+		usage:   "NODE",
+		summary: "Get the active connections of each account of a node",
+		run:     activeConnections,
+	},
+	"historical": {
+		usage:   "NODE",
+		summary: "Get the closed connections of each account of a node",
+		run:     historicalConnections,
+	},
+}
+
+func trafficTotals(a *app, fs *flag.FlagSet, args []string) (*result, error) {
+	times := timeRange(fs)
+	args, err := parse(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	startTime, endTime, err := times()
+	if err != nil {
+		return nil, err
+	}
+	c, err := a.client()
+	if err != nil {
+		return nil, err
+	}
+	totals, err := c.GetNodeTrafficTotalsContext(a.ctx, args[0], startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	return &result{
+		value:  totals,
+		header: []string{"DOWN", "UP", "ALL", "QUOTA"},
+		rows:   [][]interface{}{{totals.TrafficDown, totals.TrafficUp, totals.TrafficAll, totals.Quota}},
+	}, nil
+}
+
+func trafficByAccount(a *app, fs *flag.FlagSet, args []string) (*result, error) {
+	times := timeRange(fs)
+	args, err := parse(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	startTime, endTime, err := times()
+	if err != nil {
+		return nil, err
+	}
+	c, err := a.client()
+	if err != nil {
+		return nil, err
+	}
+	traffics, err := c.GetNodeTrafficByAccountContext(a.ctx, args[0], startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	res := &result{
+		value:  traffics,
+		header: []string{"USERNAME", "ACTIVE", "UID", "DOWN", "UP", "ALL"},
+	}
+	for _, t := range traffics {
+		res.rows = append(res.rows, []interface{}{t.Username, t.Active, t.UID, t.TrafficDown, t.TrafficUp, t.TrafficAll})
+	}
+	return res, nil
+}
+
+func activeConnections(a *app, fs *flag.FlagSet, args []string) (*result, error) {
+	args, err := parse(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	c, err := a.client()
+	if err != nil {
+		return nil, err
+	}
+	connections, err := c.GetActiveNodeConnectionsByAccountContext(a.ctx, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return connectionsResult(connections), nil
+}
+
+func historicalConnections(a *app, fs *flag.FlagSet, args []string) (*result, error) {
+	times := timeRange(fs)
+	args, err := parse(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	startTime, endTime, err := times()
+	if err != nil {
+		return nil, err
+	}
+	c, err := a.client()
+	if err != nil {
+		return nil, err
+	}
+	connections, err := c.GetHistoricalNodeConnectionsByAccountContext(a.ctx, args[0], startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	return connectionsResult(connections), nil
+}
+
+func connectionsResult(connections []*foxyproxy.NodeConnection) *result {
+	res := &result{
+		value:  connections,
+		header: []string{"USERNAME", "ACTIVE", "UID", "CONNECTIONS"},
+	}
+	for _, c := range connections {
+		res.rows = append(res.rows, []interface{}{c.Username, c.Active, c.UID, c.Connections})
+	}
+	return res
+}
@@ -0,0 +1,288 @@
+package foxyproxy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Credentials are the username and password used to authenticate api requests.
+type Credentials struct {
+	Username string `yaml:"username" json:"username"`
+	Password string `yaml:"password" json:"password"`
+}
+
+// CredentialsProvider provides the credentials of a client. Credentials is called before every
+// request attempt, so rotated credentials are picked up without creating a new client. When the
+// api responds with 401 Unauthorized, Refresh is called and, if the credentials changed, the
+// request is retried once with the new ones. Implementations must be safe for concurrent use.
+type CredentialsProvider interface {
+	// Credentials returns the current credentials. It should be cheap, e.g. by caching loaded
+	// credentials.
+	Credentials(ctx context.Context) (Credentials, error)
+	// Refresh reloads the credentials, ignoring any cache.
+	Refresh(ctx context.Context) error
+}
+
+// StaticCredentials returns a provider of fixed credentials. It is used by clients created with
+// NewClientParams.Username and Password.
+func StaticCredentials(username, password string) CredentialsProvider {
+	return staticCredentials{Username: username, Password: password}
+}
+
+type staticCredentials Credentials
+
+func (s staticCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	return Credentials(s), nil
+}
+
+func (s staticCredentials) Refresh(ctx context.Context) error {
+	return nil
+}
+
+// EnvCredentials returns a provider reading the credentials from the environment variables
+// usernameVar and passwordVar on every request. They default to FOXYPROXY_USERNAME and
+// FOXYPROXY_PASSWORD.
+func EnvCredentials(usernameVar, passwordVar string) CredentialsProvider {
+	if usernameVar == "" {
+		usernameVar = "FOXYPROXY_USERNAME"
+	}
+	if passwordVar == "" {
+		passwordVar = "FOXYPROXY_PASSWORD"
+	}
+	return &envCredentials{usernameVar: usernameVar, passwordVar: passwordVar}
+}
+
+type envCredentials struct {
+	usernameVar, passwordVar string
+}
+
+func (e *envCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	creds := Credentials{Username: os.Getenv(e.usernameVar), Password: os.Getenv(e.passwordVar)}
+	if creds.Username == "" || creds.Password == "" {
+		return Credentials{}, fmt.Errorf("foxyproxy: %s or %s is not set", e.usernameVar, e.passwordVar)
+	}
+	return creds, nil
+}
+
+func (e *envCredentials) Refresh(ctx context.Context) error {
+	return nil
+}
+
+// FileCredentialsParams is an object of optional FileCredentials parameters.
+type FileCredentialsParams struct {
+	// Interval is the minimum time between two checks of the file for changes. Defaults to 10
+	// seconds.
+	Interval time.Duration
+}
+
+// FileCredentials returns a provider reading the credentials from the file at path, a YAML or JSON
+// object with username and password keys, e.g. a mounted secret. The file is read again when its
+// modification time or size changes, which is checked at most once per params.Interval. params may
+// be nil.
+func FileCredentials(path string, params *FileCredentialsParams) CredentialsProvider {
+	interval := 10 * time.Second
+	if params != nil && params.Interval > 0 {
+		interval = params.Interval
+	}
+	f := &fileCredentials{path: path}
+	f.cache = cachedCredentials{
+		interval: interval,
+		load:     f.load,
+		changed:  f.changed,
+	}
+	return &f.cache
+}
+
+type fileCredentials struct {
+	path  string
+	cache cachedCredentials
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+}
+
+// changed reports whether the file changed since it was last loaded.
+func (f *fileCredentials) changed() bool {
+	info, err := os.Stat(f.path)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return err != nil || !info.ModTime().Equal(f.modTime) || info.Size() != f.size
+}
+
+func (f *fileCredentials) load(ctx context.Context) (Credentials, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return Credentials{}, err
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return Credentials{}, err
+	}
+	creds, err := parseCredentials(data)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("%s: %w", f.path, err)
+	}
+	f.mu.Lock()
+	f.modTime, f.size = info.ModTime(), info.Size()
+	f.mu.Unlock()
+	return creds, nil
+}
+
+// CommandCredentialsParams is an object of optional CommandCredentials parameters.
+type CommandCredentialsParams struct {
+	// TTL is how long the output of the command is cached. Defaults to 5 minutes.
+	TTL time.Duration
+}
+
+// CommandCredentials returns a provider running the command name with args, e.g. a secrets manager
+// CLI, and reading the credentials from its output, a YAML or JSON object with username and
+// password keys. The command runs again after params.TTL, or after the api rejected the
+// credentials; requests keep using the previous credentials while it runs, and if it fails. params
+// may be nil.
+func CommandCredentials(params *CommandCredentialsParams, name string, args ...string) CredentialsProvider {
+	ttl := 5 * time.Minute
+	if params != nil && params.TTL > 0 {
+		ttl = params.TTL
+	}
+	return &cachedCredentials{
+		interval: ttl,
+		load: func(ctx context.Context) (Credentials, error) {
+			var stderr bytes.Buffer
+			cmd := exec.CommandContext(ctx, name, args...)
+			cmd.Stderr = &stderr
+			out, err := cmd.Output()
+			if err != nil {
+				if msg := strings.TrimSpace(stderr.String()); msg != "" {
+					err = fmt.Errorf("%w: %s", err, msg)
+				}
+				return Credentials{}, fmt.Errorf("foxyproxy: credentials command %s: %w", name, err)
+			}
+			creds, err := parseCredentials(out)
+			if err != nil {
+				return Credentials{}, fmt.Errorf("foxyproxy: credentials command %s: %w", name, err)
+			}
+			return creds, nil
+		},
+	}
+}
+
+// loadTimeout bounds a load of cachedCredentials, which doesn't depend on the context of the
+// request that triggered it.
+const loadTimeout = time.Minute
+
+// cachedCredentials caches the credentials returned by load. They are loaded again after interval
+// if changed is nil or reports a change. Loads run without the lock held, one at a time: while
+// credentials are being reloaded, and after a failed reload, the cached ones keep being served.
+type cachedCredentials struct {
+	interval time.Duration
+	load     func(ctx context.Context) (Credentials, error)
+	changed  func() bool
+
+	mu      sync.Mutex
+	creds   Credentials
+	loaded  bool
+	checked time.Time
+	// loading is closed when the load in progress, if any, completes.
+	loading chan struct{}
+	// err is the error of the last load.
+	err error
+}
+
+func (c *cachedCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	if creds, ok := c.cached(); ok {
+		return creds, nil
+	}
+	err := c.reload(ctx)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loaded {
+		return Credentials{}, err
+	}
+	return c.creds, nil
+}
+
+func (c *cachedCredentials) Refresh(ctx context.Context) error {
+	return c.reload(ctx)
+}
+
+// cached returns the cached credentials, unless there are none or they must be loaded again.
+// changed is called without c.mu held.
+func (c *cachedCredentials) cached() (Credentials, bool) {
+	c.mu.Lock()
+	creds, loaded := c.creds, c.loaded
+	fresh := c.loading != nil || time.Since(c.checked) < c.interval
+	c.mu.Unlock()
+	if !loaded {
+		return Credentials{}, false
+	}
+	if fresh {
+		return creds, true
+	}
+	if c.changed == nil || c.changed() {
+		return Credentials{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checked = time.Now()
+	return c.creds, true
+}
+
+// reload loads the credentials, or waits for the load in progress, and returns the error of the
+// load. A failed load keeps the cached credentials, which are checked again after interval. The
+// load isn't canceled with ctx, which only bounds how long the caller waits for it: it runs for
+// all the callers waiting for it, for at most loadTimeout.
+func (c *cachedCredentials) reload(ctx context.Context) error {
+	c.mu.Lock()
+	loading := c.loading
+	if loading == nil {
+		loading = make(chan struct{})
+		c.loading = loading
+		go c.run(context.WithoutCancel(ctx), loading)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-loading:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// run loads the credentials and closes loading when done.
+func (c *cachedCredentials) run(ctx context.Context, loading chan struct{}) {
+	ctx, cancel := context.WithTimeout(ctx, loadTimeout)
+	defer cancel()
+	creds, err := c.load(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		c.creds, c.loaded = creds, true
+	}
+	c.checked, c.err, c.loading = time.Now(), err, nil
+	close(loading)
+}
+
+// parseCredentials decodes a YAML or JSON object with username and password keys.
+func parseCredentials(data []byte) (Credentials, error) {
+	creds := Credentials{}
+	if err := yaml.Unmarshal(data, &creds); err != nil {
+		return Credentials{}, fmt.Errorf("invalid credentials: %w", err)
+	}
+	if creds.Username == "" || creds.Password == "" {
+		return Credentials{}, fmt.Errorf("invalid credentials: missing username or password")
+	}
+	return creds, nil
+}
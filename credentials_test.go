@@ -0,0 +1,236 @@
+package foxyproxy_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"github.com/jsignanini/foxyproxy-reseller-go/foxyproxytest"
+)
+
+func writeCredentials(t *testing.T, path, username, password string) {
+	t.Helper()
+	data := "username: " + username + "\npassword: " + password + "\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileCredentialsRotation(t *testing.T) {
+	srv := foxyproxytest.NewServer()
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "credentials.yaml")
+	writeCredentials(t, path, srv.Username, srv.Password)
+
+	params := srv.Params()
+	params.Username, params.Password = "", ""
+	params.Credentials = foxyproxy.FileCredentials(path, &foxyproxy.FileCredentialsParams{Interval: time.Hour})
+	c, err := foxyproxy.NewClient(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetNodeCount(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the password is rotated: the cached credentials are rejected, refreshed and the request is
+	// retried once
+	srv.Password = "rotated"
+	writeCredentials(t, path, srv.Username, srv.Password)
+	if _, err := c.GetNodeCount(); err != nil {
+		t.Fatal(err)
+	}
+	if count := srv.RequestCount(); count != 3 {
+		t.Errorf("expected requests: %d, got %d", 3, count)
+	}
+
+	// unchanged credentials are not retried
+	srv.Password = "rotated-again"
+	if _, err := c.GetNodeCount(); !errors.Is(err, foxyproxy.ErrUnauthorized) {
+		t.Errorf("expected error: %v, got %v", foxyproxy.ErrUnauthorized, err)
+	}
+	if count := srv.RequestCount(); count != 4 {
+		t.Errorf("expected requests: %d, got %d", 4, count)
+	}
+}
+
+func TestFileCredentialsWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(path, []byte(`{"username": "admin", "password": "secret"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p := foxyproxy.FileCredentials(path, &foxyproxy.FileCredentialsParams{Interval: time.Nanosecond})
+	ctx := context.Background()
+	creds, err := p.Credentials(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds != (foxyproxy.Credentials{Username: "admin", Password: "secret"}) {
+		t.Errorf("unexpected credentials: %+v", creds)
+	}
+
+	writeCredentials(t, path, "admin", "new-secret")
+	if creds, err = p.Credentials(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if creds.Password != "new-secret" {
+		t.Errorf("expected the changed file to be read, got %+v", creds)
+	}
+
+	if err := os.WriteFile(path, []byte("username: admin\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Refresh(ctx); err == nil {
+		t.Error("expected an error for the missing password")
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("FOXYPROXY_USERNAME", "admin")
+	t.Setenv("FOXYPROXY_PASSWORD", "")
+	p := foxyproxy.EnvCredentials("", "")
+	if _, err := p.Credentials(context.Background()); err == nil {
+		t.Error("expected an error for the missing password")
+	}
+	t.Setenv("FOXYPROXY_PASSWORD", "secret")
+	creds, err := p.Credentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds != (foxyproxy.Credentials{Username: "admin", Password: "secret"}) {
+		t.Errorf("unexpected credentials: %+v", creds)
+	}
+}
+
+func TestCommandCredentials(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	counter := filepath.Join(t.TempDir(), "runs")
+	p := foxyproxy.CommandCredentials(nil, "sh", "-c", `echo run >> "$0"; echo '{"username": "admin", "password": "secret"}'`, counter)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		creds, err := p.Credentials(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if creds != (foxyproxy.Credentials{Username: "admin", Password: "secret"}) {
+			t.Errorf("unexpected credentials: %+v", creds)
+		}
+	}
+	if err := p.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	runs, err := os.ReadFile(counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(runs) != "run\nrun\n" {
+		t.Errorf("expected the command to run twice, got %q", runs)
+	}
+
+	p = foxyproxy.CommandCredentials(nil, "sh", "-c", "echo denied >&2; exit 1")
+	if _, err := p.Credentials(ctx); err == nil {
+		t.Error("expected an error for the failed command")
+	}
+}
+
+func TestFileCredentialsRefreshError(t *testing.T) {
+	srv := foxyproxytest.NewServer()
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "credentials.yaml")
+	writeCredentials(t, path, srv.Username, srv.Password)
+	params := srv.Params()
+	params.Username, params.Password = "", ""
+	params.Credentials = foxyproxy.FileCredentials(path, &foxyproxy.FileCredentialsParams{Interval: time.Hour})
+	c, err := foxyproxy.NewClient(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetNodeCount(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.Password = "rotated"
+	if err := os.WriteFile(path, []byte("username: admin\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetNodeCount()
+	if !errors.Is(err, foxyproxy.ErrUnauthorized) || !strings.Contains(err.Error(), "missing username or password") {
+		t.Errorf("expected an unauthorized error with the refresh error, got %v", err)
+	}
+}
+
+func TestCommandCredentialsStale(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	counter := filepath.Join(t.TempDir(), "runs")
+	// the first run succeeds, the next ones are slow and fail
+	script := `if [ -e "$0" ]; then echo run >> "$0"; sleep 0.5; exit 1; fi; echo run >> "$0"; echo '{"username": "admin", "password": "secret"}'`
+	p := foxyproxy.CommandCredentials(&foxyproxy.CommandCredentialsParams{TTL: time.Nanosecond}, "sh", "-c", script, counter)
+	ctx := context.Background()
+	expected := foxyproxy.Credentials{Username: "admin", Password: "secret"}
+	if creds, err := p.Credentials(ctx); err != nil || creds != expected {
+		t.Fatalf("unexpected credentials: (%+v, %v)", creds, err)
+	}
+
+	refreshed := make(chan error)
+	go func() {
+		refreshed <- p.Refresh(ctx)
+	}()
+	for {
+		runs, _ := os.ReadFile(counter)
+		if strings.Count(string(runs), "run") == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	start := time.Now()
+	if creds, err := p.Credentials(ctx); err != nil || creds != expected {
+		t.Errorf("expected the cached credentials during the reload, got (%+v, %v)", creds, err)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("expected the cached credentials without waiting for the reload, waited %v", elapsed)
+	}
+	if err := <-refreshed; err == nil {
+		t.Error("expected an error for the failed command")
+	}
+	if creds, err := p.Credentials(ctx); err != nil || creds != expected {
+		t.Errorf("expected the cached credentials after the failed reload, got (%+v, %v)", creds, err)
+	}
+}
+
+func TestCommandCredentialsCanceled(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	counter := filepath.Join(t.TempDir(), "runs")
+	p := foxyproxy.CommandCredentials(nil, "sh", "-c", `echo run >> "$0"; sleep 0.2; echo '{"username": "admin", "password": "secret"}'`, counter)
+	ctx, cancel := context.WithCancel(context.Background())
+	loaded := make(chan error)
+	go func() {
+		_, err := p.Credentials(ctx)
+		loaded <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-loaded; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error: %v, got %v", context.Canceled, err)
+	}
+
+	// the load started by the canceled caller still completes for the others
+	creds, err := p.Credentials(context.Background())
+	if err != nil || creds != (foxyproxy.Credentials{Username: "admin", Password: "secret"}) {
+		t.Errorf("unexpected credentials: (%+v, %v)", creds, err)
+	}
+	if runs, _ := os.ReadFile(counter); string(runs) != "run\n" {
+		t.Errorf("expected the command to run once, got %q", runs)
+	}
+}
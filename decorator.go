@@ -0,0 +1,67 @@
+package foxyproxy
+
+import (
+	"context"
+)
+
+// AroundFunc is called by a decorated API for every call it receives. It must call call, which
+// performs the operation with the specified context, and return its error, optionally wrapping it.
+// operation is the name of the called method, without the Context suffix.
+type AroundFunc func(ctx context.Context, operation string, call func(ctx context.Context) error) error
+
+// Decorate returns an API which passes every call through around before calling api. Nodes and
+// accounts returned by the decorated API perform their operations through it, as do iterators,
+// so around sees every request. For example, to log failed calls:
+//
+//	api := foxyproxy.Decorate(client, func(ctx context.Context, operation string, call func(context.Context) error) error {
+//		err := call(ctx)
+//		if err != nil {
+//			log.Printf("%s: %v", operation, err)
+//		}
+//		return err
+//	})
+func Decorate(api API, around AroundFunc) API {
+	return &decoratedAPI{
+		api:    api,
+		around: around,
+	}
+}
+
+type decoratedAPI struct {
+	api    API
+	around AroundFunc
+}
+
+func (d *decoratedAPI) Nodes(ctx context.Context, params *IteratorParams) *NodeIterator {
+	return NewNodesIterator(ctx, d, params)
+}
+
+func (d *decoratedAPI) Accounts(ctx context.Context, params *IteratorParams) *AccountIterator {
+	return NewAccountsIterator(ctx, d, params)
+}
+
+func (d *decoratedAPI) AccountsByUsername(ctx context.Context, username string, params *IteratorParams) *AccountIterator {
+	return NewAccountsByUsernameIterator(ctx, d, username, params)
+}
+
+func (d *decoratedAPI) AccountsByNode(ctx context.Context, nodeName string, params *IteratorParams) *AccountIterator {
+	return NewAccountsByNodeIterator(ctx, d, nodeName, params)
+}
+
+// bind makes the nodes or accounts in v perform their operations through d.
+func (d *decoratedAPI) bind(v interface{}) {
+	switch v := v.(type) {
+	case *Node:
+		if v != nil {
+			v.client = d
+		}
+	case []*Node:
+		for _, n := range v {
+			n.client = d
+		}
+	case []*Account:
+		for _, a := range v {
+			a.client = d
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package foxyproxy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"github.com/jsignanini/foxyproxy-reseller-go/foxyproxymock"
+)
+
+func TestDecorate(t *testing.T) {
+	mock := &foxyproxymock.API{
+		GetAllNodesContextFunc: func(ctx context.Context, index, size int) ([]*foxyproxy.Node, error) {
+			nodes := []*foxyproxy.Node{}
+			for i := index; i < 3 && i < index+size; i++ {
+				nodes = append(nodes, &foxyproxy.Node{Name: fmt.Sprintf("node-%d", i)})
+			}
+			return nodes, nil
+		},
+		GetActiveNodeConnectionTotalsContextFunc: func(ctx context.Context, nodeName string) (int, error) {
+			if nodeName == "node-2" {
+				return 0, foxyproxy.ErrServer
+			}
+			return 1, nil
+		},
+	}
+	operations := []string{}
+	api := foxyproxy.Decorate(mock, func(ctx context.Context, operation string, call func(context.Context) error) error {
+		operations = append(operations, operation)
+		return call(ctx)
+	})
+
+	it := api.Nodes(context.Background(), &foxyproxy.IteratorParams{PageSize: 2})
+	total := 0
+	for it.Next() {
+		count, err := it.Node().GetActiveConnectionTotals()
+		if err != nil && !errors.Is(err, foxyproxy.ErrServer) {
+			t.Fatal(err)
+		}
+		total += count
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 {
+		t.Errorf("expected active connections: %d, got %d", 2, total)
+	}
+	expected := []string{
+		"GetAllNodes", "GetActiveNodeConnectionTotals", "GetActiveNodeConnectionTotals",
+		"GetAllNodes", "GetActiveNodeConnectionTotals",
+	}
+	if fmt.Sprint(operations) != fmt.Sprint(expected) {
+		t.Errorf("expected operations: %v, got %v", expected, operations)
+	}
+}
+
+func TestMockUnexpectedCall(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected panic")
+		}
+	}()
+	(&foxyproxymock.API{}).GetNodeCount()
+}
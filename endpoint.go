@@ -0,0 +1,76 @@
+package foxyproxy
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// endpoint describes an api operation. The template is the request path with placeholders for
+// its parameters, e.g. /nodes/{name}/traffic/{start}/{end}.
+type endpoint struct {
+	operation string
+	method    string
+	template  string
+}
+
+var (
+	endpointGetAllNodes                           = &endpoint{"GetAllNodes", http.MethodGet, "/nodes/?index={index}&size={size}"}
+	endpointGetNode                               = &endpoint{"GetNode", http.MethodGet, "/nodes/{name}"}
+	endpointGetNodeCount                          = &endpoint{"GetNodeCount", http.MethodGet, "/nodes/count/"}
+	endpointGetDNSSuffixes                        = &endpoint{"GetDNSSuffixes", http.MethodGet, "/nodes/dns-suffixes/"}
+	endpointGetActiveNodeConnectionTotals         = &endpoint{"GetActiveNodeConnectionTotals", http.MethodGet, "/nodes/{name}/connections/"}
+	endpointGetHistoricalNodeConnectionTotals     = &endpoint{"GetHistoricalNodeConnectionTotals", http.MethodGet, "/nodes/{name}/connections/{start}/{end}/"}
+	endpointGetActiveNodeConnectionsByAccount     = &endpoint{"GetActiveNodeConnectionsByAccount", http.MethodGet, "/nodes/{name}/connections-by-account/"}
+	endpointGetHistoricalNodeConnectionsByAccount = &endpoint{"GetHistoricalNodeConnectionsByAccount", http.MethodGet, "/nodes/{name}/connections-by-account/{start}/{end}/"}
+	endpointGetNodeTrafficByAccount               = &endpoint{"GetNodeTrafficByAccount", http.MethodGet, "/nodes/{name}/traffic-by-account/{start}/{end}"}
+	endpointGetNodeTrafficTotals                  = &endpoint{"GetNodeTrafficTotals", http.MethodGet, "/nodes/{name}/traffic/{start}/{end}"}
+	endpointGetAccountsByNode                     = &endpoint{"GetAccountsByNode", http.MethodGet, "/nodes/{name}/accounts/?index={index}&size={size}"}
+	endpointGetAccounts                           = &endpoint{"GetAccounts", http.MethodGet, "/accounts/?index={index}&size={size}"}
+	endpointGetAccountsByUsername                 = &endpoint{"GetAccountsByUsername", http.MethodGet, "/accounts/{username}/?index={index}&size={size}"}
+	endpointCountAccounts                         = &endpoint{"CountAccounts", http.MethodGet, "/accounts/count/"}
+	endpointUsernameExists                        = &endpoint{"UsernameExists", http.MethodGet, "/accounts/exists/{username}/"}
+	endpointCreateAccounts                        = &endpoint{"CreateAccounts", http.MethodPost, "/accounts/"}
+	endpointCopyAccounts                          = &endpoint{"CopyAccounts", http.MethodPost, "/accounts/copy-all/{name}/"}
+	endpointDeactivateAccount                     = &endpoint{"DeactivateAccount", http.MethodPatch, "/accounts/deactivate/{username}/"}
+	endpointActivateAccount                       = &endpoint{"ActivateAccount", http.MethodPatch, "/accounts/activate/{username}/"}
+	endpointUpdatePassword                        = &endpoint{"UpdatePassword", http.MethodPatch, "/accounts/update-password/{username}"}
+	endpointDeleteAccounts                        = &endpoint{"DeleteAccounts", http.MethodDelete, "/accounts/{username}/"}
+)
+
+// request returns a request to the endpoint, replacing the placeholders of its template with args
+// in order. Times are replaced with their unix timestamp.
+func (e *endpoint) request(args ...interface{}) *Request {
+	req := &Request{
+		Operation: e.operation,
+		Method:    e.method,
+		Endpoint:  e.template,
+		Params:    map[string]string{},
+		Header:    http.Header{},
+	}
+	var path strings.Builder
+	rest := e.template
+	for _, arg := range args {
+		start := strings.IndexByte(rest, '{')
+		end := strings.IndexByte(rest, '}')
+		if start < 0 || end < start {
+			panic(fmt.Sprintf("foxyproxy: too many arguments for endpoint %s", e.template))
+		}
+		value := fmt.Sprint(arg)
+		if t, ok := arg.(time.Time); ok {
+			value = strconv.FormatInt(t.Unix(), 10)
+		}
+		req.Params[rest[start+1:end]] = value
+		path.WriteString(rest[:start])
+		path.WriteString(value)
+		rest = rest[end+1:]
+	}
+	if strings.IndexByte(rest, '{') >= 0 {
+		panic(fmt.Sprintf("foxyproxy: missing arguments for endpoint %s", e.template))
+	}
+	path.WriteString(rest)
+	req.Path = path.String()
+	return req
+}
@@ -0,0 +1,24 @@
+package foxyproxy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEndpointRequest(t *testing.T) {
+	req := endpointGetNodeTrafficTotals.request("node-a", time.Unix(100, 0), time.Unix(200, 0))
+	if req.Path != "/nodes/node-a/traffic/100/200" {
+		t.Errorf("unexpected path: %s", req.Path)
+	}
+	if req.Endpoint != "/nodes/{name}/traffic/{start}/{end}" || req.Operation != "GetNodeTrafficTotals" {
+		t.Errorf("unexpected endpoint: %s %s", req.Operation, req.Endpoint)
+	}
+	expected := map[string]string{"name": "node-a", "start": "100", "end": "200"}
+	if !reflect.DeepEqual(req.Params, expected) {
+		t.Errorf("expected params: %v, got %v", expected, req.Params)
+	}
+	if req := endpointGetAccounts.request(10, 20); req.Path != "/accounts/?index=10&size=20" {
+		t.Errorf("unexpected path: %s", req.Path)
+	}
+}
@@ -2,9 +2,32 @@ package foxyproxy
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
+)
+
+// Sentinel errors matched by *Error via errors.Is according to its status. For example:
+//
+//	if errors.Is(err, foxyproxy.ErrNotFound) {
+//		// handle missing node or account
+//	}
+var (
+	// ErrNotFound is matched by errors with a 404 Not Found status.
+	ErrNotFound = errors.New("foxyproxy: not found")
+	// ErrUnauthorized is matched by errors with a 401 Unauthorized status.
+	ErrUnauthorized = errors.New("foxyproxy: unauthorized")
+	// ErrForbidden is matched by errors with a 403 Forbidden status.
+	ErrForbidden = errors.New("foxyproxy: forbidden")
+	// ErrRateLimited is matched by errors with a 429 Too Many Requests status.
+	ErrRateLimited = errors.New("foxyproxy: rate limited")
+	// ErrValidation is matched by errors with a 400 Bad Request or 422 Unprocessable Entity status,
+	// and by invalid parameters rejected before sending a request.
+	ErrValidation = errors.New("foxyproxy: validation failed")
+	// ErrServer is matched by errors with a 5xx status.
+	ErrServer = errors.New("foxyproxy: server error")
 )
 
 // Error is an api error object.
@@ -25,7 +48,28 @@ func (e *Error) Error() string {
 	return string(errBytes)
 }
 
-// NewError generates a new Error object.
+// Is reports whether the error's status matches the target sentinel error.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Status == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.Status == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.Status == http.StatusForbidden
+	case ErrRateLimited:
+		return e.Status == http.StatusTooManyRequests
+	case ErrValidation:
+		return e.Status == http.StatusBadRequest || e.Status == http.StatusUnprocessableEntity
+	case ErrServer:
+		return e.Status >= 500 && e.Status < 600
+	default:
+		return false
+	}
+}
+
+// NewError generates a new Error object. If body is not a JSON error object, it is used as the
+// error message.
 func NewError(body io.ReadCloser) (*Error, error) {
 	bodyBytes, err := ioutil.ReadAll(body)
 	if err != nil {
@@ -33,7 +77,90 @@ func NewError(body io.ReadCloser) (*Error, error) {
 	}
 	e := &Error{}
 	if err := json.Unmarshal(bodyBytes, e); err != nil {
-		return nil, fmt.Errorf(string(bodyBytes))
+		return &Error{Message: string(bodyBytes)}, nil
 	}
 	return e, nil
 }
+
+// RequestError is returned when a request could not be sent or its response could not be decoded.
+type RequestError struct {
+	Method string
+	Path   string
+	Err    error
+}
+
+// Error returns a string representation of RequestError.
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("foxyproxy: %s %s: %v", e.Method, e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+func newValidationError(message string) error {
+	return fmt.Errorf("%w: %s", ErrValidation, message)
+}
+
+// newResponseError generates an Error from an unexpected response, filling in the status and path
+// when the response body doesn't include them.
+func newResponseError(res *http.Response) error {
+	apiError, err := NewError(res.Body)
+	if err != nil {
+		return err
+	}
+	if apiError.Status == 0 {
+		apiError.Status = res.StatusCode
+	}
+	if apiError.ErrorString == "" {
+		apiError.ErrorString = http.StatusText(res.StatusCode)
+	}
+	if apiError.Path == "" && res.Request != nil {
+		apiError.Path = res.Request.URL.Path
+	}
+	return apiError
+}
+
+// ResourceKind is a kind of api resource.
+type ResourceKind string
+
+// Resource kinds reported by NotFoundError.
+const (
+	ResourceNode    ResourceKind = "node"
+	ResourceAccount ResourceKind = "account"
+)
+
+// NotFoundError is returned when the requested node or account doesn't exist. It matches
+// ErrNotFound via errors.Is.
+type NotFoundError struct {
+	Kind ResourceKind
+	// ID identifies the missing resource: a node name for nodes, a username for accounts.
+	ID  string
+	Err *Error
+}
+
+// Error returns a string representation of NotFoundError.
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("foxyproxy: %s %q not found", e.Kind, e.ID)
+}
+
+// Is reports whether target is ErrNotFound.
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Unwrap returns the underlying api error.
+func (e *NotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// notFound converts a not found api error into a *NotFoundError for the specified resource. Other
+// errors are returned unchanged.
+func notFound(err error, kind ResourceKind, id string) error {
+	apiErr := &Error{}
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		return &NotFoundError{Kind: kind, ID: id, Err: apiErr}
+	}
+	return err
+}
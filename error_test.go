@@ -0,0 +1,74 @@
+package foxyproxy
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorIs(t *testing.T) {
+	sentinels := []error{ErrNotFound, ErrUnauthorized, ErrForbidden, ErrRateLimited, ErrValidation, ErrServer}
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusBadRequest, ErrValidation},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrForbidden},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusUnprocessableEntity, ErrValidation},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusInternalServerError, ErrServer},
+		{http.StatusBadGateway, ErrServer},
+		{http.StatusConflict, nil},
+	}
+	for _, test := range tests {
+		err := fmt.Errorf("wrapped: %w", &Error{Status: test.status})
+		for _, sentinel := range sentinels {
+			if got, want := errors.Is(err, sentinel), sentinel == test.want; got != want {
+				t.Errorf("status %d: expected errors.Is(err, %v) to be %t", test.status, sentinel, want)
+			}
+		}
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/accounts/count/":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("Bad credentials"))
+		case "/nodes/count/":
+			w.Write([]byte("not json"))
+		}
+	}))
+	defer ts.Close()
+	c := newTestClient(t, &NewClientParams{EndpointBaseURL: ts.URL})
+
+	// non JSON error bodies are still typed
+	_, err := c.CountAccounts()
+	apiErr := &Error{}
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *Error, got %T", err)
+	}
+	if !errors.Is(err, ErrUnauthorized) || apiErr.Message != "Bad credentials" || apiErr.Path != "/accounts/count/" {
+		t.Errorf("unexpected error: %v", apiErr)
+	}
+
+	// decode errors carry the method and path
+	_, err = c.GetNodeCount()
+	reqErr := &RequestError{}
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("expected *RequestError, got %T", err)
+	}
+	if reqErr.Method != http.MethodGet || reqErr.Path != "/nodes/count/" {
+		t.Errorf("expected request error for %s %s, got %s %s", http.MethodGet, "/nodes/count/", reqErr.Method, reqErr.Path)
+	}
+
+	// invalid parameters are rejected before sending the request
+	if _, err := c.GetAccounts(-1, 10); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected error: %v, got %v", ErrValidation, err)
+	}
+}
@@ -0,0 +1,105 @@
+package foxyproxy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jsignanini/foxyproxy-reseller-go/internal/parallel"
+)
+
+// DefaultParallelism is the number of concurrent calls made by ForEachNode and the ...ForAllNodes
+// methods when the specified parallelism is less than 1.
+const DefaultParallelism = 8
+
+// CollectFromNodes pages through all nodes retrieved through api and calls fn for each one of
+// them, running up to parallelism calls concurrently. It returns the results of the successful
+// calls and the errors of the failed ones, both by node name; a failed call doesn't stop the
+// others. The returned error is only set if the nodes could not be listed or ctx is done, in which
+// case the maps hold the calls completed so far.
+func CollectFromNodes[T any](ctx context.Context, api API, parallelism int, fn func(ctx context.Context, node *Node) (T, error)) (map[string]T, map[string]error, error) {
+	if parallelism < 1 {
+		parallelism = DefaultParallelism
+	}
+	var (
+		mu      sync.Mutex
+		results = map[string]T{}
+		errs    = map[string]error{}
+	)
+	it := api.Nodes(ctx, nil)
+	next := func() (*Node, bool) {
+		if !it.Next() {
+			return nil, false
+		}
+		return it.Node(), true
+	}
+	err := parallel.Run(ctx, parallelism, next, func(node *Node) {
+		v, err := fn(ctx, node)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs[node.Name] = err
+		} else {
+			results[node.Name] = v
+		}
+	})
+	if itErr := it.Err(); itErr != nil {
+		err = itErr
+	}
+	return results, errs, err
+}
+
+// ForEachNode pages through all nodes and calls fn for each one of them, running up to
+// parallelism calls concurrently. It returns the errors of the failed calls by node name; a failed
+// call doesn't stop the others. The returned error is only set if the nodes could not be listed or
+// ctx is done.
+func (c *Client) ForEachNode(ctx context.Context, parallelism int, fn func(ctx context.Context, node *Node) error) (map[string]error, error) {
+	_, errs, err := CollectFromNodes(ctx, c, parallelism, func(ctx context.Context, node *Node) (struct{}, error) {
+		return struct{}{}, fn(ctx, node)
+	})
+	return errs, err
+}
+
+// TrafficTotalsForAllNodes gets the traffic totals of all nodes between startTime and endTime,
+// inclusive, running up to DefaultParallelism calls concurrently. See CollectFromNodes for the
+// returned values.
+// See https://reseller.api.foxyproxy.com/#_node_traffic_totals.
+func (c *Client) TrafficTotalsForAllNodes(ctx context.Context, startTime, endTime time.Time) (map[string]*NodeTrafficTotals, map[string]error, error) {
+	return CollectFromNodes(ctx, c, DefaultParallelism, func(ctx context.Context, node *Node) (*NodeTrafficTotals, error) {
+		return node.GetTrafficTotalsContext(ctx, startTime, endTime)
+	})
+}
+
+// TrafficByAccountForAllNodes gets the traffic by account of all nodes between startTime and
+// endTime, inclusive, running up to DefaultParallelism calls concurrently. See CollectFromNodes for
+// the returned values.
+// See https://reseller.api.foxyproxy.com/#_node_traffic_by_account.
+func (c *Client) TrafficByAccountForAllNodes(ctx context.Context, startTime, endTime time.Time) (map[string][]*NodeTrafficAccount, map[string]error, error) {
+	return CollectFromNodes(ctx, c, DefaultParallelism, func(ctx context.Context, node *Node) ([]*NodeTrafficAccount, error) {
+		return node.GetTrafficByAccountContext(ctx, startTime, endTime)
+	})
+}
+
+// ActiveConnectionTotalsForAllNodes gets the active connection counts of all nodes, running up to
+// DefaultParallelism calls concurrently. See CollectFromNodes for the returned values.
+// See https://reseller.api.foxyproxy.com/#_active_node_connection_totals.
+func (c *Client) ActiveConnectionTotalsForAllNodes(ctx context.Context) (map[string]int, map[string]error, error) {
+	return CollectFromNodes(ctx, c, DefaultParallelism, func(ctx context.Context, node *Node) (int, error) {
+		return node.GetActiveConnectionTotalsContext(ctx)
+	})
+}
+
+// AccountsForAllNodes gets all accounts of all nodes, paging through each node's accounts and
+// running up to DefaultParallelism nodes concurrently. See CollectFromNodes for the returned
+// values.
+// See https://reseller.api.foxyproxy.com/#_get_accounts_by_node.
+func (c *Client) AccountsForAllNodes(ctx context.Context) (map[string][]*Account, map[string]error, error) {
+	return CollectFromNodes(ctx, c, DefaultParallelism, func(ctx context.Context, node *Node) ([]*Account, error) {
+		accounts := []*Account{}
+		it := node.Accounts(ctx, nil)
+		for it.Next() {
+			accounts = append(accounts, it.Account())
+		}
+		return accounts, it.Err()
+	})
+}
@@ -0,0 +1,77 @@
+package foxyproxy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"github.com/jsignanini/foxyproxy-reseller-go/foxyproxytest"
+)
+
+func TestTrafficTotalsForAllNodes(t *testing.T) {
+	srv := foxyproxytest.NewServer()
+	defer srv.Close()
+	at := time.Unix(1000, 0)
+	for i := 0; i < 150; i++ {
+		name := fmt.Sprintf("node-%03d", i)
+		srv.AddNode(&foxyproxy.Node{Name: name})
+		srv.AddTraffic(name, "john", at, foxyproxy.ByteSize(i), 0)
+	}
+	srv.InjectFault(foxyproxytest.Fault{PathPrefix: "/nodes/node-007/", Status: http.StatusInternalServerError})
+
+	totals, errs, err := srv.Client().TrafficTotalsForAllNodes(context.Background(), at, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(totals) != 149 || len(errs) != 1 {
+		t.Fatalf("expected 149 results and 1 error, got %d and %d", len(totals), len(errs))
+	}
+	if !errors.Is(errs["node-007"], foxyproxy.ErrServer) {
+		t.Errorf("expected error: %v, got %v", foxyproxy.ErrServer, errs["node-007"])
+	}
+	if totals["node-042"].TrafficUp != 42 {
+		t.Errorf("unexpected totals of node-042: %+v", totals["node-042"])
+	}
+}
+
+func TestForEachNodeParallelism(t *testing.T) {
+	srv := foxyproxytest.NewServer()
+	defer srv.Close()
+	for i := 0; i < 20; i++ {
+		srv.AddNode(&foxyproxy.Node{Name: fmt.Sprintf("node-%02d", i)})
+	}
+	var (
+		mu                sync.Mutex
+		running, maxCalls int
+	)
+	errs, err := srv.Client().ForEachNode(context.Background(), 3, func(ctx context.Context, node *foxyproxy.Node) error {
+		mu.Lock()
+		running++
+		if running > maxCalls {
+			maxCalls = running
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if node.Name == "node-05" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if maxCalls != 3 {
+		t.Errorf("expected concurrent calls: %d, got %d", 3, maxCalls)
+	}
+	if len(errs) != 1 || errs["node-05"] == nil {
+		t.Errorf("expected error of node-05, got %v", errs)
+	}
+}
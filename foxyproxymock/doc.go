@@ -0,0 +1,9 @@
+// Package foxyproxymock provides a mock implementation of foxyproxy.API for unit tests which do
+// not need a fake server (see package foxyproxytest for one).
+//
+//	api := &foxyproxymock.API{
+//		GetNodeCountContextFunc: func(ctx context.Context) (int, error) {
+//			return 3, nil
+//		},
+//	}
+package foxyproxymock
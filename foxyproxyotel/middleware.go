@@ -0,0 +1,141 @@
+// Package foxyproxyotel provides OpenTelemetry tracing for foxyproxy clients. The middleware opens
+// a client span for every client method, named after the operation (e.g. GetNodeTrafficTotals),
+// and propagates the trace context on the outgoing requests:
+//
+//	client.Use(foxyproxyotel.Middleware(nil))
+//
+// Usernames are never recorded in clear. If MiddlewareParams.UsernameKey is set, spans carry their
+// HMAC-SHA-256 keyed with it instead, so the spans of a username can be correlated without the
+// usernames being recoverable by hashing candidate ones.
+package foxyproxyotel
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strconv"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope name of the tracer.
+const ScopeName = "github.com/jsignanini/foxyproxy-reseller-go/foxyproxyotel"
+
+// Attribute keys recorded on spans, in addition to the semantic HTTP attributes.
+const (
+	NodeNameKey     = attribute.Key("foxyproxy.node.name")
+	UsernameHashKey = attribute.Key("foxyproxy.username.hash")
+	PageIndexKey    = attribute.Key("foxyproxy.page.index")
+	PageSizeKey     = attribute.Key("foxyproxy.page.size")
+	ResultCountKey  = attribute.Key("foxyproxy.result.count")
+)
+
+// MiddlewareParams is an object of optional middleware parameters.
+type MiddlewareParams struct {
+	// TracerProvider creates the tracer. Defaults to the global tracer provider.
+	TracerProvider trace.TracerProvider
+	// Propagator injects the trace context into outgoing requests. Defaults to the global
+	// propagator.
+	Propagator propagation.TextMapPropagator
+	// UsernameKey is the secret key of the HMAC recorded on spans in place of usernames (see
+	// HashUsername). If empty, usernames are not recorded.
+	UsernameKey []byte
+}
+
+// Middleware returns a client middleware tracing every request. Install it with
+// foxyproxy.Client.Use. params may be nil.
+func Middleware(params *MiddlewareParams) foxyproxy.Middleware {
+	if params == nil {
+		params = &MiddlewareParams{}
+	}
+	tp := params.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	propagator := params.Propagator
+	if propagator == nil {
+		propagator = otel.GetTextMapPropagator()
+	}
+	tracer := tp.Tracer(ScopeName)
+	return func(next foxyproxy.Doer) foxyproxy.Doer {
+		return foxyproxy.DoerFunc(func(ctx context.Context, req *foxyproxy.Request) (*foxyproxy.Response, error) {
+			ctx, span := tracer.Start(ctx, req.Operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(requestAttributes(req, params.UsernameKey)...),
+			)
+			defer span.End()
+			propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			res, err := next.Do(ctx, req)
+			var apiError *foxyproxy.Error
+			switch {
+			case errors.As(err, &apiError):
+				span.SetAttributes(attribute.Int("http.response.status_code", apiError.Status))
+			case err == nil:
+				span.SetAttributes(attribute.Int("http.response.status_code", res.StatusCode))
+				if count, ok := resultCount(req.Result); ok {
+					span.SetAttributes(ResultCountKey.Int(count))
+				}
+			}
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return res, err
+		})
+	}
+}
+
+func requestAttributes(req *foxyproxy.Request, usernameKey []byte) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("http.request.method", req.Method),
+		attribute.String("http.route", req.Endpoint),
+	}
+	if name, ok := req.Params["name"]; ok {
+		attrs = append(attrs, NodeNameKey.String(name))
+	}
+	if username, ok := req.Params["username"]; ok && len(usernameKey) > 0 {
+		attrs = append(attrs, UsernameHashKey.String(HashUsername(usernameKey, username)))
+	}
+	if index, err := strconv.Atoi(req.Params["index"]); err == nil {
+		attrs = append(attrs, PageIndexKey.Int(index))
+	}
+	if size, err := strconv.Atoi(req.Params["size"]); err == nil {
+		attrs = append(attrs, PageSizeKey.Int(size))
+	}
+	return attrs
+}
+
+// HashUsername returns the hex-encoded HMAC-SHA-256 of username keyed with key, as recorded on
+// spans.
+func HashUsername(key []byte, username string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(username))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// resultCount returns the number of items of a decoded response: the length of a list, or the
+// count returned by count and write operations.
+func resultCount(result interface{}) (int, bool) {
+	v := reflect.ValueOf(result)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		return v.Len(), true
+	case reflect.Struct:
+		if count := v.FieldByName("Count"); count.IsValid() && count.Kind() == reflect.Int {
+			return int(count.Int()), true
+		}
+	}
+	return 0, false
+}
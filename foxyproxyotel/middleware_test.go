@@ -0,0 +1,93 @@
+package foxyproxyotel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestMiddleware(t *testing.T) {
+	var traceparent string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		switch r.URL.Path {
+		case "/accounts/john/":
+			w.Write([]byte(`[{"username":"john"},{"username":"john"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	recorder := tracetest.NewSpanRecorder()
+	c, err := foxyproxy.NewClient(&foxyproxy.NewClientParams{
+		Username:        "admin",
+		Password:        "12345",
+		DomainHeader:    "example-inc",
+		EndpointBaseURL: ts.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Use(Middleware(&MiddlewareParams{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		Propagator:     propagation.TraceContext{},
+		UsernameKey:    []byte("secret"),
+	}))
+
+	if _, err := c.GetAccountsByUsernameContext(context.Background(), "john", 0, 10); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetNode("node-a"); err == nil {
+		t.Fatal("expected not found error")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected spans: %d, got %d", 2, len(spans))
+	}
+	if spans[0].Name() != "GetAccountsByUsername" || spans[1].Name() != "GetNode" {
+		t.Errorf("unexpected span names: %s, %s", spans[0].Name(), spans[1].Name())
+	}
+	attrs := attribute.NewSet(spans[0].Attributes()...)
+	for _, expected := range []attribute.KeyValue{
+		UsernameHashKey.String(HashUsername([]byte("secret"), "john")),
+		PageIndexKey.Int(0),
+		PageSizeKey.Int(10),
+		ResultCountKey.Int(2),
+		attribute.String("http.route", "/accounts/{username}/?index={index}&size={size}"),
+	} {
+		if v, ok := attrs.Value(expected.Key); !ok || v != expected.Value {
+			t.Errorf("expected attribute %s=%v, got %v", expected.Key, expected.Value.Emit(), v.Emit())
+		}
+	}
+	attrs = attribute.NewSet(spans[1].Attributes()...)
+	if v, ok := attrs.Value(NodeNameKey); !ok || v.AsString() != "node-a" {
+		t.Errorf("expected node name attribute, got %v", v.Emit())
+	}
+	if spans[1].Status().Code != codes.Error {
+		t.Errorf("expected error status, got %v", spans[1].Status())
+	}
+	if traceparent == "" || traceparent[3:35] != spans[1].SpanContext().TraceID().String() {
+		t.Errorf("expected trace context of %s, got %q", spans[1].SpanContext().TraceID(), traceparent)
+	}
+}
+
+func TestMiddlewareWithoutUsernameKey(t *testing.T) {
+	req := &foxyproxy.Request{Method: http.MethodGet, Params: map[string]string{"username": "john"}}
+	for _, attr := range requestAttributes(req, nil) {
+		if attr.Key == UsernameHashKey {
+			t.Errorf("expected no username attribute without a key, got %v", attr.Value.Emit())
+		}
+	}
+	if HashUsername([]byte("a"), "john") == HashUsername([]byte("b"), "john") {
+		t.Error("expected the username hash to depend on the key")
+	}
+}
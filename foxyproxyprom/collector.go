@@ -0,0 +1,126 @@
+// Package foxyproxyprom provides Prometheus metrics for foxyproxy clients. A Collector counts the
+// requests of the clients it is installed on, and can be registered in any Prometheus registry:
+//
+//	collector := foxyproxyprom.NewCollector(nil)
+//	prometheus.MustRegister(collector)
+//	client.Use(collector.Middleware())
+//
+// Metrics are labeled by HTTP method, endpoint template (e.g. /nodes/{name}/traffic/{start}/{end})
+// and status class (2xx, 4xx, 5xx, or "error" when no response was received).
+package foxyproxyprom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CollectorParams is an object of optional collector parameters.
+type CollectorParams struct {
+	// Namespace prefixes the metric names. Defaults to "foxyproxy".
+	Namespace string
+	// ConstLabels are added to all metrics, e.g. to tell apart the clients of several reseller
+	// domains.
+	ConstLabels prometheus.Labels
+	// Buckets are the buckets of the request duration histogram, in seconds. Defaults to
+	// prometheus.DefBuckets.
+	Buckets []float64
+}
+
+// Collector collects request metrics of foxyproxy clients. It implements prometheus.Collector.
+type Collector struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+var labels = []string{"method", "endpoint", "status_class"}
+
+// NewCollector generates a new collector. params may be nil.
+func NewCollector(params *CollectorParams) *Collector {
+	if params == nil {
+		params = &CollectorParams{}
+	}
+	namespace := params.Namespace
+	if namespace == "" {
+		namespace = "foxyproxy"
+	}
+	buckets := params.Buckets
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "requests_total",
+			Help:        "Total number of reseller api requests.",
+			ConstLabels: params.ConstLabels,
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "request_errors_total",
+			Help:        "Total number of failed reseller api requests.",
+			ConstLabels: params.ConstLabels,
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Name:        "request_duration_seconds",
+			Help:        "Duration of reseller api requests, including retries.",
+			ConstLabels: params.ConstLabels,
+			Buckets:     buckets,
+		}, labels),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.errors.Describe(ch)
+	c.duration.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.errors.Collect(ch)
+	c.duration.Collect(ch)
+}
+
+// Middleware returns a client middleware recording the metrics of every request. Install it with
+// foxyproxy.Client.Use.
+func (c *Collector) Middleware() foxyproxy.Middleware {
+	return func(next foxyproxy.Doer) foxyproxy.Doer {
+		return foxyproxy.DoerFunc(func(ctx context.Context, req *foxyproxy.Request) (*foxyproxy.Response, error) {
+			start := time.Now()
+			res, err := next.Do(ctx, req)
+			values := []string{req.Method, req.Endpoint, statusClass(res, err)}
+			c.requests.WithLabelValues(values...).Inc()
+			c.duration.WithLabelValues(values...).Observe(time.Since(start).Seconds())
+			if err != nil {
+				c.errors.WithLabelValues(values...).Inc()
+			}
+			return res, err
+		})
+	}
+}
+
+// statusClass returns the class of the response status, e.g. 4xx, or "error" if no response was
+// received.
+func statusClass(res *foxyproxy.Response, err error) string {
+	status := 0
+	var apiError *foxyproxy.Error
+	switch {
+	case errors.As(err, &apiError):
+		status = apiError.Status
+	case err == nil && res != nil:
+		status = res.StatusCode
+	}
+	if status < 100 || status > 599 {
+		return "error"
+	}
+	return fmt.Sprintf("%dxx", status/100)
+}
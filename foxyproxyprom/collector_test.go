@@ -0,0 +1,55 @@
+package foxyproxyprom
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"github.com/jsignanini/foxyproxy-reseller-go/foxyproxytest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	srv := foxyproxytest.NewServer()
+	defer srv.Close()
+	srv.AddNode(&foxyproxy.Node{Name: "node-a"})
+	srv.AddNode(&foxyproxy.Node{Name: "node-b"})
+	c := srv.Client()
+	collector := NewCollector(&CollectorParams{ConstLabels: prometheus.Labels{"tenant": "example"}})
+	c.Use(collector.Middleware())
+
+	now := time.Now()
+	for _, name := range []string{"node-a", "node-b"} {
+		if _, err := c.GetNodeTrafficTotals(name, now, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.GetNode("node-c"); err == nil {
+		t.Fatal("expected not found error")
+	}
+	srv.InjectFault(foxyproxytest.Fault{Status: http.StatusInternalServerError, Count: 1})
+	if _, err := c.GetNodeCount(); err == nil {
+		t.Fatal("expected server error")
+	}
+
+	expected := `
+# HELP foxyproxy_requests_total Total number of reseller api requests.
+# TYPE foxyproxy_requests_total counter
+foxyproxy_requests_total{endpoint="/nodes/count/",method="GET",status_class="5xx",tenant="example"} 1
+foxyproxy_requests_total{endpoint="/nodes/{name}",method="GET",status_class="4xx",tenant="example"} 1
+foxyproxy_requests_total{endpoint="/nodes/{name}/traffic/{start}/{end}",method="GET",status_class="2xx",tenant="example"} 2
+# HELP foxyproxy_request_errors_total Total number of failed reseller api requests.
+# TYPE foxyproxy_request_errors_total counter
+foxyproxy_request_errors_total{endpoint="/nodes/count/",method="GET",status_class="5xx",tenant="example"} 1
+foxyproxy_request_errors_total{endpoint="/nodes/{name}",method="GET",status_class="4xx",tenant="example"} 1
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "foxyproxy_requests_total", "foxyproxy_request_errors_total"); err != nil {
+		t.Error(err)
+	}
+	if count := testutil.CollectAndCount(collector, "foxyproxy_request_duration_seconds"); count != 3 {
+		t.Errorf("expected duration series: %d, got %d", 3, count)
+	}
+}
@@ -0,0 +1,471 @@
+package foxyproxytest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+)
+
+type nodeJSON struct {
+	Active      bool                     `json:"active"`
+	Name        string                   `json:"name"`
+	IPAddress   string                   `json:"ipAddress"`
+	Country     string                   `json:"country"`
+	CountryCode string                   `json:"countryCode"`
+	City        string                   `json:"city"`
+	Services    []*foxyproxy.NodeService `json:"services"`
+}
+
+type accountJSON struct {
+	Active   bool      `json:"active"`
+	Node     *nodeJSON `json:"node,omitempty"`
+	UID      string    `json:"uid"`
+	Username string    `json:"username"`
+}
+
+type connectionJSON struct {
+	UID         string `json:"uid"`
+	Active      bool   `json:"active"`
+	Username    string `json:"username"`
+	Connections int    `json:"connections"`
+}
+
+type trafficJSON struct {
+	UID         string             `json:"uid,omitempty"`
+	Active      bool               `json:"active,omitempty"`
+	Username    string             `json:"username,omitempty"`
+	TrafficDown foxyproxy.ByteSize `json:"trafficDown"`
+	TrafficUp   foxyproxy.ByteSize `json:"trafficUp"`
+	TrafficAll  foxyproxy.ByteSize `json:"trafficAll"`
+	Quota       foxyproxy.ByteSize `json:"quota,omitempty"`
+}
+
+type countJSON struct {
+	Count int `json:"count"`
+}
+
+// requestBody is the union of all request bodies accepted by the api.
+type requestBody struct {
+	Username       string `json:"username"`
+	Password       string `json:"password"`
+	IncludeHistory bool   `json:"includeHistory"`
+	foxyproxy.CommonProperties
+}
+
+func toNodeJSON(n *foxyproxy.Node) *nodeJSON {
+	return &nodeJSON{
+		Active:      n.Active,
+		Name:        n.Name,
+		IPAddress:   n.IPAddress,
+		Country:     n.Country,
+		CountryCode: n.CountryCode,
+		City:        n.City,
+		Services:    n.Services,
+	}
+}
+
+func (s *Server) toAccountJSON(a *AccountState) *accountJSON {
+	aj := &accountJSON{
+		Active:   a.Active,
+		UID:      a.UID,
+		Username: a.Username,
+	}
+	if n, ok := s.nodes[a.NodeName]; ok {
+		aj.Node = toNodeJSON(n)
+	}
+	return aj
+}
+
+// route handles a request which passed authentication and returns the response body. It must be
+// called with s.mu held.
+func (s *Server) route(method string, u *url.URL, body []byte) (interface{}, *httpError) {
+	rb := &requestBody{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, rb); err != nil {
+			return nil, errorf(http.StatusBadRequest, "invalid body: %v", err)
+		}
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	allow := func(allowed string) *httpError {
+		if method != allowed {
+			return errorf(http.StatusMethodNotAllowed, "method %s not allowed", method)
+		}
+		return nil
+	}
+	var (
+		v    interface{}
+		herr *httpError
+	)
+	switch {
+	case len(segments) == 1 && segments[0] == "nodes":
+		if herr = allow(http.MethodGet); herr == nil {
+			v, herr = s.listNodes(u.Query())
+		}
+	case len(segments) == 2 && segments[0] == "nodes" && segments[1] == "count":
+		if herr = allow(http.MethodGet); herr == nil {
+			v = &countJSON{Count: len(s.nodes)}
+		}
+	case len(segments) == 2 && segments[0] == "nodes" && segments[1] == "dns-suffixes":
+		if herr = allow(http.MethodGet); herr == nil {
+			v = append([]string{}, s.dnsSuffixes...)
+		}
+	case len(segments) >= 2 && segments[0] == "nodes":
+		if herr = allow(http.MethodGet); herr == nil {
+			v, herr = s.routeNode(segments[1], segments[2:], u.Query())
+		}
+	case len(segments) == 1 && segments[0] == "accounts":
+		switch method {
+		case http.MethodGet:
+			v, herr = s.listAccounts(u.Query(), func(*AccountState) bool { return true })
+		case http.MethodPost:
+			v, herr = s.createAccounts(rb)
+		default:
+			herr = allow(http.MethodGet)
+		}
+	case len(segments) == 2 && segments[0] == "accounts" && segments[1] == "count":
+		if herr = allow(http.MethodGet); herr == nil {
+			v = &countJSON{Count: len(s.accounts)}
+		}
+	case len(segments) == 3 && segments[0] == "accounts":
+		v, herr = s.routeAccountAction(method, segments[1], segments[2], rb)
+	case len(segments) == 2 && segments[0] == "accounts":
+		username := segments[1]
+		switch method {
+		case http.MethodGet:
+			if !s.usernameExists(username) {
+				return nil, errorf(http.StatusNotFound, "account %s not found", username)
+			}
+			v, herr = s.listAccounts(u.Query(), func(a *AccountState) bool { return a.Username == username })
+		case http.MethodDelete:
+			v, herr = s.deleteAccounts(username, rb)
+		default:
+			herr = allow(http.MethodGet)
+		}
+	default:
+		herr = errorf(http.StatusNotFound, "no handler for %s", u.Path)
+	}
+	if herr != nil {
+		return nil, herr
+	}
+	return v, nil
+}
+
+func (s *Server) routeNode(nodeName string, segments []string, query url.Values) (interface{}, *httpError) {
+	if _, ok := s.nodes[nodeName]; !ok {
+		return nil, errorf(http.StatusNotFound, "node %s not found", nodeName)
+	}
+	switch {
+	case len(segments) == 0:
+		return toNodeJSON(s.nodes[nodeName]), nil
+	case len(segments) == 1 && segments[0] == "accounts":
+		return s.listAccounts(query, func(a *AccountState) bool { return a.NodeName == nodeName })
+	case len(segments) == 1 && segments[0] == "connections":
+		total := 0
+		for _, count := range s.active[nodeName] {
+			total += count
+		}
+		return &countJSON{Count: total}, nil
+	case len(segments) == 1 && segments[0] == "connections-by-account":
+		connections := []*connectionJSON{}
+		for _, a := range s.accounts {
+			if count := s.active[nodeName][a.Username]; a.NodeName == nodeName && count > 0 {
+				connections = append(connections, &connectionJSON{UID: a.UID, Active: a.Active, Username: a.Username, Connections: count})
+			}
+		}
+		return connections, nil
+	case len(segments) == 3 && segments[0] == "connections":
+		start, end, herr := timeRange(segments[1], segments[2])
+		if herr != nil {
+			return nil, herr
+		}
+		total := 0
+		for _, c := range s.connections {
+			if c.nodeName == nodeName && inRange(c.at, start, end) {
+				total += c.count
+			}
+		}
+		return &countJSON{Count: total}, nil
+	case len(segments) == 3 && segments[0] == "connections-by-account":
+		start, end, herr := timeRange(segments[1], segments[2])
+		if herr != nil {
+			return nil, herr
+		}
+		connections := []*connectionJSON{}
+		byUsername := map[string]*connectionJSON{}
+		for _, c := range s.connections {
+			if c.nodeName != nodeName || !inRange(c.at, start, end) {
+				continue
+			}
+			cj, ok := byUsername[c.username]
+			if !ok {
+				cj = &connectionJSON{Username: c.username}
+				if a := s.findAccount(nodeName, c.username); a != nil {
+					cj.UID, cj.Active = a.UID, a.Active
+				}
+				byUsername[c.username] = cj
+				connections = append(connections, cj)
+			}
+			cj.Connections += c.count
+		}
+		return connections, nil
+	case len(segments) == 3 && segments[0] == "traffic":
+		start, end, herr := timeRange(segments[1], segments[2])
+		if herr != nil {
+			return nil, herr
+		}
+		traffic := &trafficJSON{Quota: s.quotas[nodeName]}
+		for _, t := range s.traffic {
+			if t.nodeName == nodeName && inRange(t.at, start, end) {
+				traffic.TrafficUp += t.up
+				traffic.TrafficDown += t.down
+			}
+		}
+		traffic.TrafficAll = traffic.TrafficUp + traffic.TrafficDown
+		return traffic, nil
+	case len(segments) == 3 && segments[0] == "traffic-by-account":
+		start, end, herr := timeRange(segments[1], segments[2])
+		if herr != nil {
+			return nil, herr
+		}
+		traffics := []*trafficJSON{}
+		for _, a := range s.accounts {
+			if a.NodeName != nodeName {
+				continue
+			}
+			traffic := &trafficJSON{UID: a.UID, Active: a.Active, Username: a.Username}
+			for _, t := range s.traffic {
+				if t.nodeName == nodeName && t.username == a.Username && inRange(t.at, start, end) {
+					traffic.TrafficUp += t.up
+					traffic.TrafficDown += t.down
+				}
+			}
+			traffic.TrafficAll = traffic.TrafficUp + traffic.TrafficDown
+			traffics = append(traffics, traffic)
+		}
+		return traffics, nil
+	default:
+		return nil, errorf(http.StatusNotFound, "no handler for node %s", nodeName)
+	}
+}
+
+func (s *Server) routeAccountAction(method, action, target string, rb *requestBody) (interface{}, *httpError) {
+	switch action {
+	case "exists":
+		if method != http.MethodGet {
+			break
+		}
+		if !s.usernameExists(target) {
+			return nil, errorf(http.StatusNotFound, "account %s not found", target)
+		}
+		return &countJSON{Count: 1}, nil
+	case "activate", "deactivate":
+		if method != http.MethodPatch {
+			break
+		}
+		active := action == "activate"
+		return s.updateAccounts(target, rb, func(a *AccountState) {
+			a.Active = active
+			if rb.Comment != "" {
+				a.Comment = rb.Comment
+			}
+		})
+	case "update-password":
+		if method != http.MethodPatch {
+			break
+		}
+		if len(rb.Password) < 3 || len(rb.Password) > 127 {
+			return nil, errorf(http.StatusBadRequest, "password must be between 3 and 127 characters long")
+		}
+		return s.updateAccounts(target, rb, func(a *AccountState) {
+			a.Password = rb.Password
+		})
+	case "copy-all":
+		if method != http.MethodPost {
+			break
+		}
+		return s.copyAccounts(target, rb)
+	default:
+		return nil, errorf(http.StatusNotFound, "no handler for account action %s", action)
+	}
+	return nil, errorf(http.StatusMethodNotAllowed, "method %s not allowed", method)
+}
+
+func (s *Server) listNodes(query url.Values) (interface{}, *httpError) {
+	nodes := s.sortedNodes()
+	start, end, herr := page(query, len(nodes))
+	if herr != nil {
+		return nil, herr
+	}
+	items := []*nodeJSON{}
+	for _, n := range nodes[start:end] {
+		items = append(items, toNodeJSON(n))
+	}
+	return items, nil
+}
+
+func (s *Server) listAccounts(query url.Values, match func(*AccountState) bool) (interface{}, *httpError) {
+	matched := []*AccountState{}
+	for _, a := range s.accounts {
+		if match(a) {
+			matched = append(matched, a)
+		}
+	}
+	start, end, herr := page(query, len(matched))
+	if herr != nil {
+		return nil, herr
+	}
+	items := []*accountJSON{}
+	for _, a := range matched[start:end] {
+		items = append(items, s.toAccountJSON(a))
+	}
+	return items, nil
+}
+
+func (s *Server) createAccounts(rb *requestBody) (interface{}, *httpError) {
+	if rb.Username == "" {
+		return nil, errorf(http.StatusBadRequest, "username is required")
+	}
+	if len(rb.Password) < 3 || len(rb.Password) > 127 {
+		return nil, errorf(http.StatusBadRequest, "password must be between 3 and 127 characters long")
+	}
+	nodeNames := rb.NodeNames
+	if len(nodeNames) == 0 {
+		for _, n := range s.sortedNodes() {
+			nodeNames = append(nodeNames, n.Name)
+		}
+	}
+	for _, nodeName := range nodeNames {
+		if _, ok := s.nodes[nodeName]; !ok {
+			return nil, errorf(http.StatusNotFound, "node %s not found", nodeName)
+		}
+		if s.findAccount(nodeName, rb.Username) != nil {
+			return nil, errorf(http.StatusBadRequest, "account %s already exists on node %s", rb.Username, nodeName)
+		}
+	}
+	created := []*accountJSON{}
+	for _, nodeName := range nodeNames {
+		s.addAccount(AccountState{
+			NodeName: nodeName,
+			Username: rb.Username,
+			Password: rb.Password,
+			Active:   true,
+			Comment:  rb.Comment,
+		})
+		created = append(created, s.toAccountJSON(s.accounts[len(s.accounts)-1]))
+	}
+	return created, nil
+}
+
+// updateAccounts applies update to all accounts with the specified username on the nodes selected
+// by the request body.
+func (s *Server) updateAccounts(username string, rb *requestBody, update func(*AccountState)) (interface{}, *httpError) {
+	if !s.usernameExists(username) {
+		return nil, errorf(http.StatusNotFound, "account %s not found", username)
+	}
+	count := 0
+	for _, a := range s.accounts {
+		if a.Username == username && selected(a.NodeName, rb.NodeNames) {
+			update(a)
+			count++
+		}
+	}
+	return &countJSON{Count: count}, nil
+}
+
+func (s *Server) deleteAccounts(username string, rb *requestBody) (interface{}, *httpError) {
+	if !s.usernameExists(username) {
+		return nil, errorf(http.StatusNotFound, "account %s not found", username)
+	}
+	count := 0
+	accounts := s.accounts[:0]
+	for _, a := range s.accounts {
+		if a.Username == username && selected(a.NodeName, rb.NodeNames) {
+			count++
+			continue
+		}
+		accounts = append(accounts, a)
+	}
+	s.accounts = accounts
+	if rb.IncludeHistory {
+		traffic := s.traffic[:0]
+		for _, t := range s.traffic {
+			if t.username != username || !selected(t.nodeName, rb.NodeNames) {
+				traffic = append(traffic, t)
+			}
+		}
+		s.traffic = traffic
+		connections := s.connections[:0]
+		for _, c := range s.connections {
+			if c.username != username || !selected(c.nodeName, rb.NodeNames) {
+				connections = append(connections, c)
+			}
+		}
+		s.connections = connections
+	}
+	return &countJSON{Count: count}, nil
+}
+
+func (s *Server) copyAccounts(fromNode string, rb *requestBody) (interface{}, *httpError) {
+	if _, ok := s.nodes[fromNode]; !ok {
+		return nil, errorf(http.StatusNotFound, "node %s not found", fromNode)
+	}
+	for _, nodeName := range rb.NodeNames {
+		if _, ok := s.nodes[nodeName]; !ok {
+			return nil, errorf(http.StatusNotFound, "node %s not found", nodeName)
+		}
+	}
+	count := 0
+	for _, a := range append([]*AccountState{}, s.accounts...) {
+		if a.NodeName != fromNode {
+			continue
+		}
+		for _, nodeName := range rb.NodeNames {
+			if nodeName == fromNode || s.findAccount(nodeName, a.Username) != nil {
+				continue
+			}
+			s.addAccount(AccountState{
+				NodeName: nodeName,
+				Username: a.Username,
+				Password: a.Password,
+				Active:   a.Active,
+				Comment:  rb.Comment,
+			})
+			count++
+		}
+	}
+	return &countJSON{Count: count}, nil
+}
+
+func (s *Server) findAccount(nodeName, username string) *AccountState {
+	for _, a := range s.accounts {
+		if a.NodeName == nodeName && a.Username == username {
+			return a
+		}
+	}
+	return nil
+}
+
+func (s *Server) usernameExists(username string) bool {
+	for _, a := range s.accounts {
+		if a.Username == username {
+			return true
+		}
+	}
+	return false
+}
+
+// selected reports whether nodeName is one of nodeNames. An empty nodeNames selects all nodes.
+func selected(nodeName string, nodeNames []string) bool {
+	if len(nodeNames) == 0 {
+		return true
+	}
+	for _, n := range nodeNames {
+		if n == nodeName {
+			return true
+		}
+	}
+	return false
+}
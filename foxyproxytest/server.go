@@ -0,0 +1,396 @@
+// Package foxyproxytest provides an in-memory fake of the FoxyProxy reseller api for testing code
+// which depends on a foxyproxy.Client.
+//
+//	srv := foxyproxytest.NewServer()
+//	defer srv.Close()
+//	srv.AddNode(&foxyproxy.Node{Name: "node-1", Active: true})
+//	srv.AddAccount(&foxyproxytest.AccountState{NodeName: "node-1", Username: "john", Password: "secret"})
+//	client := srv.Client()
+package foxyproxytest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+)
+
+// Default credentials accepted by a new Server.
+const (
+	DefaultUsername = "admin"
+	DefaultPassword = "password"
+	DefaultDomain   = "foxyproxytest"
+)
+
+// Server is a fake reseller api server. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// Username, Password and Domain are the credentials and X-DOMAIN header value required from
+	// clients. They must not be changed while requests are in flight.
+	Username, Password string
+	Domain             string
+
+	mu          sync.Mutex
+	nodes       map[string]*foxyproxy.Node
+	quotas      map[string]foxyproxy.ByteSize
+	accounts    []*AccountState
+	nextUID     int
+	dnsSuffixes []string
+	traffic     []trafficSample
+	connections []connectionSample
+	active      map[string]map[string]int
+	faults      []*Fault
+	latency     time.Duration
+	requests    int
+}
+
+// AccountState is the state of an account on a node.
+type AccountState struct {
+	UID      string
+	NodeName string
+	Username string
+	Password string
+	Active   bool
+	Comment  string
+}
+
+// Fault is a failure injected into the server's responses.
+type Fault struct {
+	// PathPrefix limits the fault to requests whose path starts with it. Empty matches all
+	// requests.
+	PathPrefix string
+	// Status is the response status. Zero only delays the response by Latency.
+	Status int
+	// RetryAfter is the value of the Retry-After response header, if not empty.
+	RetryAfter string
+	// Latency delays the response.
+	Latency time.Duration
+	// Count is the number of requests affected by the fault. Zero affects all requests until
+	// ClearFaults is called.
+	Count int
+}
+
+type trafficSample struct {
+	nodeName, username string
+	at                 time.Time
+	up, down           foxyproxy.ByteSize
+}
+
+type connectionSample struct {
+	nodeName, username string
+	at                 time.Time
+	count              int
+}
+
+// NewServer starts and returns a new fake server with default credentials and no data. The caller
+// should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		Username: DefaultUsername,
+		Password: DefaultPassword,
+		Domain:   DefaultDomain,
+		nodes:    map[string]*foxyproxy.Node{},
+		quotas:   map[string]foxyproxy.ByteSize{},
+		active:   map[string]map[string]int{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Params returns the parameters of a client connecting to the server.
+func (s *Server) Params() *foxyproxy.NewClientParams {
+	return &foxyproxy.NewClientParams{
+		Username:        s.Username,
+		Password:        s.Password,
+		DomainHeader:    s.Domain,
+		EndpointBaseURL: s.URL,
+		HTTPClient:      s.Server.Client(),
+	}
+}
+
+// Client returns a client connected to the server. It panics if the credentials of the server
+// were cleared.
+func (s *Server) Client() *foxyproxy.Client {
+	c, err := foxyproxy.NewClient(s.Params())
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// AddNode adds a node to the reseller pool, replacing any node with the same name.
+func (s *Server) AddNode(node *foxyproxy.Node) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := *node
+	s.nodes[n.Name] = &n
+}
+
+// SetQuota sets the traffic quota of a node.
+func (s *Server) SetQuota(nodeName string, quota foxyproxy.ByteSize) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quotas[nodeName] = quota
+}
+
+// SetDNSSuffixes sets the DNS suffixes of the reseller pool.
+func (s *Server) SetDNSSuffixes(suffixes []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dnsSuffixes = append([]string{}, suffixes...)
+}
+
+// AddAccount adds an account to a node and returns its UID, which is generated if empty.
+func (s *Server) AddAccount(account *AccountState) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addAccount(*account)
+}
+
+// Accounts returns a snapshot of all accounts.
+func (s *Server) Accounts() []AccountState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	accounts := make([]AccountState, len(s.accounts))
+	for i, a := range s.accounts {
+		accounts[i] = *a
+	}
+	return accounts
+}
+
+// AddTraffic records traffic, in bytes, of an account on a node at the specified time.
+func (s *Server) AddTraffic(nodeName, username string, at time.Time, up, down foxyproxy.ByteSize) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traffic = append(s.traffic, trafficSample{nodeName: nodeName, username: username, at: at, up: up, down: down})
+}
+
+// AddConnections records closed connections of an account on a node at the specified time.
+func (s *Server) AddConnections(nodeName, username string, at time.Time, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connections = append(s.connections, connectionSample{nodeName: nodeName, username: username, at: at, count: count})
+}
+
+// SetActiveConnections sets the number of active connections of an account on a node.
+func (s *Server) SetActiveConnections(nodeName, username string, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active[nodeName] == nil {
+		s.active[nodeName] = map[string]int{}
+	}
+	s.active[nodeName][username] = count
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// InjectFault adds a fault to the server. Faults are matched in the order they were added.
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := fault
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all injected faults and latency.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+	s.latency = 0
+}
+
+// RequestCount returns the number of requests received by the server, including rejected ones.
+func (s *Server) RequestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *Server) addAccount(a AccountState) string {
+	if a.UID == "" {
+		s.nextUID++
+		a.UID = fmt.Sprintf("uid-%d", s.nextUID)
+	}
+	s.accounts = append(s.accounts, &a)
+	return a.UID
+}
+
+// httpError is an error response.
+type httpError struct {
+	status  int
+	message string
+}
+
+func errorf(status int, format string, args ...interface{}) *httpError {
+	return &httpError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	latency := s.latency
+	fault := s.matchFault(r.URL.Path)
+	s.mu.Unlock()
+
+	if fault != nil {
+		latency += fault.Latency
+	}
+	if latency > 0 && !wait(r.Context(), latency) {
+		return
+	}
+	if fault != nil && fault.Status != 0 {
+		if fault.RetryAfter != "" {
+			w.Header().Set("Retry-After", fault.RetryAfter)
+		}
+		writeError(w, r, errorf(fault.Status, "injected fault"))
+		return
+	}
+	if herr := s.checkRequest(r); herr != nil {
+		writeError(w, r, herr)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, r, errorf(http.StatusBadRequest, "%v", err))
+		return
+	}
+
+	s.mu.Lock()
+	v, herr := s.route(r.Method, r.URL, body)
+	s.mu.Unlock()
+	if herr != nil {
+		writeError(w, r, herr)
+		return
+	}
+	w.Header().Set("Content-Type", foxyproxy.ContentType)
+	json.NewEncoder(w).Encode(v)
+}
+
+// matchFault returns the first fault matching path, consuming one of its uses.
+func (s *Server) matchFault(path string) *Fault {
+	for i, f := range s.faults {
+		if !strings.HasPrefix(path, f.PathPrefix) {
+			continue
+		}
+		if f.Count > 0 {
+			f.Count--
+			if f.Count == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		fault := *f
+		return &fault
+	}
+	return nil
+}
+
+// checkRequest verifies the credentials and headers sent by the client.
+func (s *Server) checkRequest(r *http.Request) *httpError {
+	username, password, ok := r.BasicAuth()
+	if !ok || username != s.Username || password != s.Password {
+		return errorf(http.StatusUnauthorized, "Bad credentials")
+	}
+	if r.Header.Get("X-DOMAIN") != s.Domain {
+		return errorf(http.StatusForbidden, "Unknown domain")
+	}
+	if r.Header.Get("Accept") != foxyproxy.ContentType {
+		return errorf(http.StatusNotAcceptable, "Accept must be %s", foxyproxy.ContentType)
+	}
+	if r.ContentLength != 0 && r.Header.Get("Content-Type") != foxyproxy.ContentType {
+		return errorf(http.StatusUnsupportedMediaType, "Content-Type must be %s", foxyproxy.ContentType)
+	}
+	return nil
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, herr *httpError) {
+	w.Header().Set("Content-Type", foxyproxy.ContentType)
+	w.WriteHeader(herr.status)
+	json.NewEncoder(w).Encode(&foxyproxy.Error{
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Status:      herr.status,
+		ErrorString: http.StatusText(herr.status),
+		Message:     herr.message,
+		Path:        r.URL.Path,
+	})
+}
+
+func wait(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// sortedNodes returns all nodes sorted lexicographically by name.
+func (s *Server) sortedNodes() []*foxyproxy.Node {
+	nodes := make([]*foxyproxy.Node, 0, len(s.nodes))
+	for _, n := range s.nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes
+}
+
+// page parses the index and size query parameters and returns the bounds of the page.
+func page(query map[string][]string, total int) (int, int, *httpError) {
+	index, err := strconv.Atoi(first(query["index"]))
+	if err != nil || index < 0 {
+		return 0, 0, errorf(http.StatusBadRequest, "invalid index")
+	}
+	size, err := strconv.Atoi(first(query["size"]))
+	if err != nil || size < 0 || size > 100 {
+		return 0, 0, errorf(http.StatusBadRequest, "invalid size")
+	}
+	start, end := index, index+size
+	if start > total {
+		start = total
+	}
+	if end > total {
+		end = total
+	}
+	return start, end, nil
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// timeRange parses start and end unix timestamps.
+func timeRange(start, end string) (time.Time, time.Time, *httpError) {
+	s, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return time.Time{}, time.Time{}, errorf(http.StatusBadRequest, "invalid start time")
+	}
+	e, err := strconv.ParseInt(end, 10, 64)
+	if err != nil {
+		return time.Time{}, time.Time{}, errorf(http.StatusBadRequest, "invalid end time")
+	}
+	return time.Unix(s, 0), time.Unix(e, 0), nil
+}
+
+func inRange(at, start, end time.Time) bool {
+	return !at.Before(start) && !at.After(end)
+}
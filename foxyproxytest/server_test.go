@@ -0,0 +1,197 @@
+package foxyproxytest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+)
+
+func newSeededServer() *Server {
+	s := NewServer()
+	s.AddNode(&foxyproxy.Node{Name: "node-b", Active: true, Country: "Germany", City: "Berlin"})
+	s.AddNode(&foxyproxy.Node{Name: "node-a", Active: true, Country: "France", City: "Paris"})
+	s.AddAccount(&AccountState{NodeName: "node-a", Username: "john", Password: "secret", Active: true})
+	s.AddAccount(&AccountState{NodeName: "node-a", Username: "jane", Password: "secret", Active: true})
+	s.AddAccount(&AccountState{NodeName: "node-b", Username: "john", Password: "secret", Active: true})
+	return s
+}
+
+func TestServerNodes(t *testing.T) {
+	s := newSeededServer()
+	defer s.Close()
+	s.SetQuota("node-a", 1000)
+	s.SetActiveConnections("node-a", "john", 2)
+	at := time.Unix(1000, 0)
+	s.AddTraffic("node-a", "john", at, 10, 20)
+	s.AddTraffic("node-a", "jane", at.Add(time.Hour), 1, 2)
+	s.AddConnections("node-a", "john", at, 3)
+	c := s.Client()
+
+	nodes, err := c.GetAllNodes(0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 || nodes[0].Name != "node-a" || nodes[1].Name != "node-b" {
+		t.Fatalf("expected nodes sorted by name, got %v", nodes)
+	}
+	if count, err := c.GetNodeCount(); err != nil || count != 2 {
+		t.Errorf("expected node count: 2, got (%d, %v)", count, err)
+	}
+	node, err := c.GetNode("node-b")
+	if err != nil || node.City != "Berlin" {
+		t.Errorf("expected node-b in Berlin, got (%+v, %v)", node, err)
+	}
+	if count, err := node.GetActiveConnectionTotals(); err != nil || count != 0 {
+		t.Errorf("expected no active connections on node-b, got (%d, %v)", count, err)
+	}
+	if count, err := nodes[0].GetActiveConnectionTotals(); err != nil || count != 2 {
+		t.Errorf("expected 2 active connections on node-a, got (%d, %v)", count, err)
+	}
+	connections, err := nodes[0].GetActiveConnectionsByAccount()
+	if err != nil || len(connections) != 1 || connections[0].Username != "john" {
+		t.Errorf("expected active connections of john, got (%v, %v)", connections, err)
+	}
+	if count, err := nodes[0].GetHistoricalConnectionTotals(at, at); err != nil || count != 3 {
+		t.Errorf("expected 3 historical connections, got (%d, %v)", count, err)
+	}
+	totals, err := nodes[0].GetTrafficTotals(at, at.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totals.TrafficUp != 11 || totals.TrafficDown != 22 || totals.TrafficAll != 33 || totals.Quota != 1000 {
+		t.Errorf("unexpected traffic totals: %+v", totals)
+	}
+	traffics, err := nodes[0].GetTrafficByAccount(at, at)
+	if err != nil || len(traffics) != 2 || traffics[0].TrafficAll != 30 || traffics[1].TrafficAll != 0 {
+		t.Errorf("unexpected traffic by account: (%v, %v)", traffics, err)
+	}
+	if _, err := c.GetNode("node-c"); !errors.Is(err, foxyproxy.ErrNotFound) {
+		t.Errorf("expected error: %v, got %v", foxyproxy.ErrNotFound, err)
+	}
+}
+
+func TestServerAccounts(t *testing.T) {
+	s := newSeededServer()
+	defer s.Close()
+	c := s.Client()
+
+	if count, err := c.CountAccounts(); err != nil || count != 3 {
+		t.Errorf("expected account count: 3, got (%d, %v)", count, err)
+	}
+	accounts, err := c.GetAccountsByUsername("john", 0, 10)
+	if err != nil || len(accounts) != 2 {
+		t.Fatalf("expected 2 accounts for john, got (%v, %v)", accounts, err)
+	}
+	if count, err := accounts[1].Deactivate(); err != nil || count != 1 {
+		t.Errorf("expected 1 deactivated account, got (%d, %v)", count, err)
+	}
+	if count, err := c.UpdatePassword("john", "new-secret"); err != nil || count != 2 {
+		t.Errorf("expected 2 updated passwords, got (%d, %v)", count, err)
+	}
+	if _, err := c.CreateAccounts("max", "secret", &foxyproxy.CommonProperties{NodeNames: []string{"node-b"}}); err != nil {
+		t.Error(err)
+	}
+	if count, err := c.CopyAccounts("node-a", []string{"node-b"}); err != nil || count != 1 {
+		t.Errorf("expected 1 copied account, got (%d, %v)", count, err)
+	}
+	if count, err := c.DeleteAccounts("max", true); err != nil || count != 1 {
+		t.Errorf("expected 1 deleted account, got (%d, %v)", count, err)
+	}
+	if exists, err := c.UsernameExists("max"); err != nil || exists {
+		t.Errorf("expected max not to exist, got (%t, %v)", exists, err)
+	}
+
+	expected := map[string]AccountState{
+		"node-a/john": {Username: "john", NodeName: "node-a", Password: "new-secret", Active: true},
+		"node-a/jane": {Username: "jane", NodeName: "node-a", Password: "secret", Active: true},
+		"node-b/john": {Username: "john", NodeName: "node-b", Password: "new-secret", Active: false},
+		"node-b/jane": {Username: "jane", NodeName: "node-b", Password: "secret", Active: true},
+	}
+	state := s.Accounts()
+	if len(state) != len(expected) {
+		t.Fatalf("expected accounts: %d, got %d", len(expected), len(state))
+	}
+	for _, a := range state {
+		e, ok := expected[a.NodeName+"/"+a.Username]
+		a.UID, a.Comment = "", ""
+		if !ok || a != e {
+			t.Errorf("unexpected account: %+v", a)
+		}
+	}
+}
+
+func TestServerChecks(t *testing.T) {
+	s := newSeededServer()
+	defer s.Close()
+
+	params := s.Params()
+	params.Password = "wrong"
+	c, err := foxyproxy.NewClient(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetNodeCount(); !errors.Is(err, foxyproxy.ErrUnauthorized) {
+		t.Errorf("expected error: %v, got %v", foxyproxy.ErrUnauthorized, err)
+	}
+	params = s.Params()
+	params.DomainHeader = "other"
+	if c, err = foxyproxy.NewClient(params); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetNodeCount(); !errors.Is(err, foxyproxy.ErrForbidden) {
+		t.Errorf("expected error: %v, got %v", foxyproxy.ErrForbidden, err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL+"/nodes/count/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.SetBasicAuth(s.Username, s.Password)
+	req.Header.Set("X-DOMAIN", s.Domain)
+	req.Header.Set("Accept", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotAcceptable {
+		t.Errorf("expected status: %d, got %d", http.StatusNotAcceptable, res.StatusCode)
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	s := newSeededServer()
+	defer s.Close()
+	s.InjectFault(Fault{PathPrefix: "/nodes/", Status: http.StatusTooManyRequests, RetryAfter: "0", Count: 1})
+	params := s.Params()
+	params.RetryPolicy = &foxyproxy.RetryPolicy{MaxAttempts: 2}
+	c, err := foxyproxy.NewClient(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first request is throttled and retried
+	if _, err := c.GetNodeCount(); err != nil {
+		t.Error(err)
+	}
+	if count := s.RequestCount(); count != 2 {
+		t.Errorf("expected requests: %d, got %d", 2, count)
+	}
+
+	s.InjectFault(Fault{Status: http.StatusInternalServerError})
+	if _, err := c.CountAccounts(); !errors.Is(err, foxyproxy.ErrServer) {
+		t.Errorf("expected error: %v, got %v", foxyproxy.ErrServer, err)
+	}
+	s.ClearFaults()
+
+	s.SetLatency(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.CountAccountsContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error: %v, got %v", context.DeadlineExceeded, err)
+	}
+}
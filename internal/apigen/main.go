@@ -0,0 +1,249 @@
+// Command apigen generates the decorator of foxyproxy.API (decorator_gen.go) and its mock
+// implementation (foxyproxymock/api_gen.go) from the API interface declared in api.go. It is run
+// by go generate from the root of the module.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"regexp"
+	"strings"
+)
+
+const header = "// Code generated by go run ./internal/apigen; DO NOT EDIT.\n\n"
+
+type param struct {
+	name, typ string
+}
+
+type method struct {
+	name    string
+	params  []param
+	results []string
+}
+
+// hasContext reports whether the first parameter of the method is a context.
+func (m *method) hasContext() bool {
+	return len(m.params) > 0 && m.params[0].typ == "context.Context"
+}
+
+// returnsError reports whether the last result of the method is an error.
+func (m *method) returnsError() bool {
+	return len(m.results) > 0 && m.results[len(m.results)-1] == "error"
+}
+
+func (m *method) paramList(qualify bool) string {
+	params := make([]string, len(m.params))
+	for i, p := range m.params {
+		params[i] = fmt.Sprintf("%s %s", p.name, typeName(p.typ, qualify))
+	}
+	return strings.Join(params, ", ")
+}
+
+func (m *method) argList() string {
+	args := []string{}
+	for _, p := range m.params {
+		args = append(args, p.name)
+	}
+	return strings.Join(args, ", ")
+}
+
+func (m *method) resultList(qualify bool) string {
+	results := make([]string, len(m.results))
+	for i, r := range m.results {
+		results[i] = typeName(r, qualify)
+	}
+	if len(results) == 1 {
+		return results[0]
+	}
+	return "(" + strings.Join(results, ", ") + ")"
+}
+
+func (m *method) funcType(qualify bool) string {
+	return fmt.Sprintf("func(%s) %s", m.paramList(qualify), m.resultList(qualify))
+}
+
+// withBackground prepends a background context to args.
+func withBackground(args string) string {
+	if args == "" {
+		return "context.Background()"
+	}
+	return "context.Background(), " + args
+}
+
+var exportedIdent = regexp.MustCompile(`(^|[^.\w])([A-Z]\w*)`)
+
+// typeName returns typ, qualifying identifiers declared in package foxyproxy if qualify is set.
+func typeName(typ string, qualify bool) string {
+	if !qualify {
+		return typ
+	}
+	return exportedIdent.ReplaceAllString(typ, "${1}foxyproxy.${2}")
+}
+
+func main() {
+	methods, err := parseAPI("api.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := write("decorator_gen.go", generateDecorator(methods)); err != nil {
+		log.Fatal(err)
+	}
+	if err := write("foxyproxymock/api_gen.go", generateMock(methods)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func parseAPI(filename string) ([]*method, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	var iface *ast.InterfaceType
+	ast.Inspect(f, func(n ast.Node) bool {
+		if ts, ok := n.(*ast.TypeSpec); ok && ts.Name.Name == "API" {
+			iface, _ = ts.Type.(*ast.InterfaceType)
+		}
+		return iface == nil
+	})
+	if iface == nil {
+		return nil, fmt.Errorf("%s: API interface not found", filename)
+	}
+	exprString := func(expr ast.Expr) string {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, fset, expr)
+		return buf.String()
+	}
+	methods := []*method{}
+	for _, field := range iface.Methods.List {
+		ft := field.Type.(*ast.FuncType)
+		m := &method{name: field.Names[0].Name}
+		for _, p := range ft.Params.List {
+			for _, name := range p.Names {
+				m.params = append(m.params, param{name: name.Name, typ: exprString(p.Type)})
+			}
+		}
+		if ft.Results != nil {
+			for _, r := range ft.Results.List {
+				m.results = append(m.results, exprString(r.Type))
+			}
+		}
+		methods = append(methods, m)
+	}
+	return methods, nil
+}
+
+func write(filename string, src []byte) error {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("%s: %v\n%s", filename, err, src)
+	}
+	return ioutil.WriteFile(filename, formatted, 0644)
+}
+
+func imports(body string, extra ...string) string {
+	pkgs := []string{}
+	for _, pkg := range []string{"context", "time"} {
+		if strings.Contains(body, pkg+".") {
+			pkgs = append(pkgs, fmt.Sprintf("%q", pkg))
+		}
+	}
+	pkgs = append(pkgs, extra...)
+	return "import (\n" + strings.Join(pkgs, "\n") + "\n)\n\n"
+}
+
+// bindable lists the result types whose values are rebound to the decorated api.
+var bindable = map[string]bool{"*Node": true, "[]*Node": true, "[]*Account": true}
+
+func generateDecorator(methods []*method) []byte {
+	byName := map[string]*method{}
+	for _, m := range methods {
+		byName[m.name] = m
+	}
+	var body bytes.Buffer
+	for _, m := range methods {
+		if !m.returnsError() {
+			// implemented by hand
+			continue
+		}
+		fmt.Fprintf(&body, "func (d *decoratedAPI) %s(%s) %s {\n", m.name, m.paramList(false), m.resultList(false))
+		if ctxMethod, ok := byName[m.name+"Context"]; ok && !m.hasContext() && ctxMethod.hasContext() {
+			fmt.Fprintf(&body, "return d.%s(%s)\n}\n\n", ctxMethod.name, withBackground(m.argList()))
+			continue
+		}
+		ctx := "context.Background()"
+		if m.hasContext() {
+			ctx = "ctx"
+		}
+		results := []string{}
+		for i := range m.results[:len(m.results)-1] {
+			results = append(results, fmt.Sprintf("r%d", i))
+			fmt.Fprintf(&body, "var r%d %s\n", i, m.results[i])
+		}
+		operation := strings.TrimSuffix(m.name, "Context")
+		fmt.Fprintf(&body, "err := d.around(%s, %q, func(ctx context.Context) error {\n", ctx, operation)
+		fmt.Fprintf(&body, "var err error\n%s = d.api.%s(%s)\n", strings.Join(append(results, "err"), ", "), m.name, m.argList())
+		if len(results) > 0 && bindable[m.results[0]] {
+			fmt.Fprintf(&body, "d.bind(r0)\n")
+		}
+		fmt.Fprintf(&body, "return err\n})\n")
+		fmt.Fprintf(&body, "return %s\n}\n\n", strings.Join(append(results, "err"), ", "))
+	}
+	var src bytes.Buffer
+	src.WriteString(header)
+	src.WriteString("package foxyproxy\n\n")
+	src.WriteString(imports(body.String()))
+	src.Write(body.Bytes())
+	return src.Bytes()
+}
+
+func generateMock(methods []*method) []byte {
+	byName := map[string]*method{}
+	for _, m := range methods {
+		byName[m.name] = m
+	}
+	var fields, body bytes.Buffer
+	for _, m := range methods {
+		fmt.Fprintf(&fields, "%sFunc %s\n", m.name, m.funcType(true))
+
+		fmt.Fprintf(&body, "// %s calls %sFunc.", m.name, m.name)
+		ctxMethod, fallback := byName[m.name+"Context"]
+		fallback = fallback && !m.hasContext() && ctxMethod.hasContext()
+		switch {
+		case fallback:
+			fmt.Fprintf(&body, " If it is nil, %sFunc is called with a background context.", ctxMethod.name)
+		case !m.returnsError():
+			fmt.Fprintf(&body, " If it is nil, the returned iterator pages through m.")
+		}
+		fmt.Fprintf(&body, "\nfunc (m *API) %s(%s) %s {\n", m.name, m.paramList(true), m.resultList(true))
+		fmt.Fprintf(&body, "if m.%sFunc != nil {\nreturn m.%sFunc(%s)\n}\n", m.name, m.name, m.argList())
+		switch {
+		case fallback:
+			fmt.Fprintf(&body, "if m.%sFunc != nil {\nreturn m.%sFunc(%s)\n}\n", ctxMethod.name, ctxMethod.name, withBackground(m.argList()))
+		case !m.returnsError():
+			// iterators page through the mock
+			args := strings.Replace(m.argList(), "ctx", "ctx, m", 1)
+			fmt.Fprintf(&body, "return foxyproxy.New%sIterator(%s)\n}\n\n", m.name, args)
+			continue
+		}
+		fmt.Fprintf(&body, "panic(%q)\n}\n\n", "foxyproxymock: unexpected call to "+m.name)
+	}
+	var src bytes.Buffer
+	src.WriteString(header)
+	src.WriteString("package foxyproxymock\n\n")
+	src.WriteString(imports(body.String(), "", `foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"`))
+	src.WriteString("// API is a mock implementation of foxyproxy.API. Each method calls the function field of the\n")
+	src.WriteString("// same name suffixed with Func, and panics if it is nil.\n")
+	fmt.Fprintf(&src, "type API struct {\n%s}\n\n", fields.String())
+	src.WriteString("var _ foxyproxy.API = (*API)(nil)\n\n")
+	src.Write(body.Bytes())
+	return src.Bytes()
+}
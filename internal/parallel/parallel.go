@@ -0,0 +1,55 @@
+// Package parallel runs calls concurrently with a bound on the number of calls in flight. It is
+// shared by the fan-out helpers of the module.
+package parallel
+
+import (
+	"context"
+	"sync"
+)
+
+// Run calls fn with each value returned by next until next reports false, running up to limit
+// calls concurrently; a limit less than 1 runs one call at a time. No call is started once ctx is
+// done. Run returns after all started calls returned, with ctx.Err().
+func Run[T any](ctx context.Context, limit int, next func() (T, bool), fn func(v T)) error {
+	if limit < 1 {
+		limit = 1
+	}
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, limit)
+	)
+	for {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		v, ok := next()
+		if !ok {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(v)
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// ForEach calls fn with the index and value of each element of items, as Run.
+func ForEach[T any](ctx context.Context, limit int, items []T, fn func(i int, v T)) error {
+	i := -1
+	return Run(ctx, limit, func() (int, bool) {
+		i++
+		return i, i < len(items)
+	}, func(i int) {
+		fn(i, items[i])
+	})
+}
@@ -0,0 +1,57 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestForEach(t *testing.T) {
+	var (
+		mu                sync.Mutex
+		running, maxCalls int
+	)
+	items := make([]int, 50)
+	done := make([]bool, len(items))
+	err := ForEach(context.Background(), 4, items, func(i int, v int) {
+		mu.Lock()
+		running++
+		if running > maxCalls {
+			maxCalls = running
+		}
+		mu.Unlock()
+		done[i] = true
+		mu.Lock()
+		running--
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if maxCalls > 4 {
+		t.Errorf("expected at most %d concurrent calls, got %d", 4, maxCalls)
+	}
+	for i, ok := range done {
+		if !ok {
+			t.Errorf("expected item %d to be called", i)
+		}
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := ForEach(ctx, 1, make([]int, 10), func(i int, v int) {
+		calls++
+		if i == 2 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error: %v, got %v", context.Canceled, err)
+	}
+	if calls != 3 {
+		t.Errorf("expected calls: %d, got %d", 3, calls)
+	}
+}
@@ -0,0 +1,65 @@
+//go:build go1.23
+
+package foxyproxy
+
+import (
+	"context"
+	"iter"
+)
+
+// AllNodes returns a range-over-func iterator over all nodes in the reseller pool. Iteration stops
+// after yielding the first error.
+//
+//	for node, err := range client.AllNodes(ctx, nil) {
+//		if err != nil {
+//			// handle error
+//		}
+//		// ...
+//	}
+func (c *Client) AllNodes(ctx context.Context, params *IteratorParams) iter.Seq2[*Node, error] {
+	return func(yield func(*Node, error) bool) {
+		it := c.Nodes(ctx, params)
+		for it.Next() {
+			if !yield(it.Node(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// AllAccounts returns a range-over-func iterator over all accounts. Iteration stops after yielding
+// the first error.
+func (c *Client) AllAccounts(ctx context.Context, params *IteratorParams) iter.Seq2[*Account, error] {
+	return accountSeq(func() *AccountIterator { return c.Accounts(ctx, params) })
+}
+
+// AllAccountsByUsername returns a range-over-func iterator over all accounts with the specified
+// username. Iteration stops after yielding the first error.
+func (c *Client) AllAccountsByUsername(ctx context.Context, username string, params *IteratorParams) iter.Seq2[*Account, error] {
+	return accountSeq(func() *AccountIterator { return c.AccountsByUsername(ctx, username, params) })
+}
+
+// AllAccountsByNode returns a range-over-func iterator over all accounts on the specified
+// nodeName. Iteration stops after yielding the first error.
+func (c *Client) AllAccountsByNode(ctx context.Context, nodeName string, params *IteratorParams) iter.Seq2[*Account, error] {
+	return accountSeq(func() *AccountIterator { return c.AccountsByNode(ctx, nodeName, params) })
+}
+
+// accountSeq returns an iterator over the accounts of the iterator returned by newIterator, which
+// is called each time the sequence is ranged over.
+func accountSeq(newIterator func() *AccountIterator) iter.Seq2[*Account, error] {
+	return func(yield func(*Account, error) bool) {
+		it := newIterator()
+		for it.Next() {
+			if !yield(it.Account(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
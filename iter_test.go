@@ -0,0 +1,53 @@
+//go:build go1.23
+
+package foxyproxy
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAllAccounts(t *testing.T) {
+	ts, _ := newPagingServer(120)
+	defer ts.Close()
+	c := newTestClient(t, &NewClientParams{EndpointBaseURL: ts.URL})
+	count := 0
+	for account, err := range c.AllAccounts(context.Background(), &IteratorParams{Prefetch: true}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if account.client != c {
+			t.Error("expected account client to be populated")
+		}
+		count++
+		if count == 110 {
+			break
+		}
+	}
+	if count != 110 {
+		t.Errorf("expected accounts: %d, got %d", 110, count)
+	}
+}
+
+func TestAllAccountsByNodeReusable(t *testing.T) {
+	ts, requests := newPagingServer(30)
+	defer ts.Close()
+	c := newTestClient(t, &NewClientParams{EndpointBaseURL: ts.URL})
+	seq := c.AllAccountsByNode(context.Background(), "node-1", &IteratorParams{Prefetch: true})
+	if r := atomic.LoadInt32(requests); r != 0 {
+		t.Errorf("expected no requests before ranging, got %d", r)
+	}
+	for i := 0; i < 2; i++ {
+		count := 0
+		for _, err := range seq {
+			if err != nil {
+				t.Fatal(err)
+			}
+			count++
+		}
+		if count != 30 {
+			t.Errorf("expected accounts: %d, got %d", 30, count)
+		}
+	}
+}
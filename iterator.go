@@ -0,0 +1,235 @@
+package foxyproxy
+
+import (
+	"context"
+)
+
+// maxPageSize is the maximum page size accepted by the api.
+const maxPageSize = 100
+
+// IteratorParams is an object of optional iterator parameters.
+type IteratorParams struct {
+	// PageSize is the number of items requested per page. Defaults to the maximum of 100.
+	PageSize int
+	// Prefetch requests the next page in the background while the current one is iterated.
+	Prefetch bool
+}
+
+// NodeIterator pages through nodes. Call Next to advance the iterator and Node to get the current
+// node:
+//
+//	it := client.Nodes(ctx, nil)
+//	for it.Next() {
+//		node := it.Node()
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		// handle error
+//	}
+type NodeIterator struct {
+	pager *pager
+	page  []*Node
+	node  *Node
+}
+
+// Nodes returns an iterator over all nodes in the reseller pool.
+// See https://reseller.api.foxyproxy.com/#_get_all_nodes.
+func (c *Client) Nodes(ctx context.Context, params *IteratorParams) *NodeIterator {
+	return NewNodesIterator(ctx, c, params)
+}
+
+// NewNodesIterator returns an iterator over all nodes retrieved through api. It is useful to
+// implement API.Nodes in fakes and wrappers.
+func NewNodesIterator(ctx context.Context, api API, params *IteratorParams) *NodeIterator {
+	return &NodeIterator{
+		pager: newPager(ctx, params, func(ctx context.Context, index, size int) (interface{}, int, error) {
+			nodes, err := api.GetAllNodesContext(ctx, index, size)
+			return nodes, len(nodes), err
+		}),
+	}
+}
+
+// Next advances the iterator to the next node, fetching a new page if needed. It returns false
+// when there are no more nodes or an error occurred.
+func (it *NodeIterator) Next() bool {
+	for len(it.page) == 0 {
+		page, ok := it.pager.nextPage()
+		if !ok {
+			it.node = nil
+			return false
+		}
+		it.page = page.([]*Node)
+	}
+	it.node, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Node returns the current node.
+func (it *NodeIterator) Node() *Node {
+	return it.node
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *NodeIterator) Err() error {
+	return it.pager.err
+}
+
+// AccountIterator pages through accounts. Call Next to advance the iterator and Account to get
+// the current account.
+type AccountIterator struct {
+	pager   *pager
+	page    []*Account
+	account *Account
+}
+
+// Accounts returns an iterator over all accounts.
+// See https://reseller.api.foxyproxy.com/#_get_accounts.
+func (c *Client) Accounts(ctx context.Context, params *IteratorParams) *AccountIterator {
+	return NewAccountsIterator(ctx, c, params)
+}
+
+// AccountsByUsername returns an iterator over all accounts with the specified username.
+// See https://reseller.api.foxyproxy.com/#_get_accounts_by_username.
+func (c *Client) AccountsByUsername(ctx context.Context, username string, params *IteratorParams) *AccountIterator {
+	return NewAccountsByUsernameIterator(ctx, c, username, params)
+}
+
+// AccountsByNode returns an iterator over all accounts on the specified nodeName.
+// See https://reseller.api.foxyproxy.com/#_get_accounts_by_node.
+func (c *Client) AccountsByNode(ctx context.Context, nodeName string, params *IteratorParams) *AccountIterator {
+	return NewAccountsByNodeIterator(ctx, c, nodeName, params)
+}
+
+// Accounts returns an iterator over all accounts on the node.
+// See https://reseller.api.foxyproxy.com/#_get_accounts_by_node.
+func (n *Node) Accounts(ctx context.Context, params *IteratorParams) *AccountIterator {
+	return n.client.AccountsByNode(ctx, n.Name, params)
+}
+
+// NewAccountsIterator returns an iterator over all accounts retrieved through api. It is useful to
+// implement API.Accounts in fakes and wrappers.
+func NewAccountsIterator(ctx context.Context, api API, params *IteratorParams) *AccountIterator {
+	return newAccountIterator(ctx, params, api.GetAccountsContext)
+}
+
+// NewAccountsByUsernameIterator returns an iterator over all accounts with the specified username
+// retrieved through api. It is useful to implement API.AccountsByUsername in fakes and wrappers.
+func NewAccountsByUsernameIterator(ctx context.Context, api API, username string, params *IteratorParams) *AccountIterator {
+	return newAccountIterator(ctx, params, func(ctx context.Context, index, size int) ([]*Account, error) {
+		return api.GetAccountsByUsernameContext(ctx, username, index, size)
+	})
+}
+
+// NewAccountsByNodeIterator returns an iterator over all accounts on the specified nodeName
+// retrieved through api. It is useful to implement API.AccountsByNode in fakes and wrappers.
+func NewAccountsByNodeIterator(ctx context.Context, api API, nodeName string, params *IteratorParams) *AccountIterator {
+	return newAccountIterator(ctx, params, func(ctx context.Context, index, size int) ([]*Account, error) {
+		return api.GetAccountsByNodeContext(ctx, nodeName, index, size)
+	})
+}
+
+func newAccountIterator(ctx context.Context, params *IteratorParams, fetch func(ctx context.Context, index, size int) ([]*Account, error)) *AccountIterator {
+	return &AccountIterator{
+		pager: newPager(ctx, params, func(ctx context.Context, index, size int) (interface{}, int, error) {
+			accounts, err := fetch(ctx, index, size)
+			return accounts, len(accounts), err
+		}),
+	}
+}
+
+// Next advances the iterator to the next account, fetching a new page if needed. It returns false
+// when there are no more accounts or an error occurred.
+func (it *AccountIterator) Next() bool {
+	for len(it.page) == 0 {
+		page, ok := it.pager.nextPage()
+		if !ok {
+			it.account = nil
+			return false
+		}
+		it.page = page.([]*Account)
+	}
+	it.account, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Account returns the current account.
+func (it *AccountIterator) Account() *Account {
+	return it.account
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *AccountIterator) Err() error {
+	return it.pager.err
+}
+
+// pager fetches consecutive pages until a page shorter than the page size is returned.
+type pager struct {
+	ctx      context.Context
+	size     int
+	index    int
+	prefetch bool
+	fetch    func(ctx context.Context, index, size int) (interface{}, int, error)
+	pending  chan pageResult
+	done     bool
+	err      error
+}
+
+type pageResult struct {
+	items interface{}
+	n     int
+	err   error
+}
+
+func newPager(ctx context.Context, params *IteratorParams, fetch func(ctx context.Context, index, size int) (interface{}, int, error)) *pager {
+	p := &pager{
+		ctx:   ctx,
+		size:  maxPageSize,
+		fetch: fetch,
+	}
+	if params != nil {
+		if params.PageSize > 0 && params.PageSize < maxPageSize {
+			p.size = params.PageSize
+		}
+		p.prefetch = params.Prefetch
+	}
+	return p
+}
+
+// nextPage returns the next non-empty page, or false if there are no more pages.
+func (p *pager) nextPage() (interface{}, bool) {
+	if p.done {
+		return nil, false
+	}
+	var res pageResult
+	if p.pending != nil {
+		res = <-p.pending
+		p.pending = nil
+	} else {
+		res = p.load(p.index)
+	}
+	if res.err != nil {
+		p.err = res.err
+		p.done = true
+		return nil, false
+	}
+	p.index += res.n
+	if res.n < p.size {
+		p.done = true
+	} else if p.prefetch {
+		// buffered so the goroutine never blocks if iteration stops early
+		pending := make(chan pageResult, 1)
+		go func(index int) {
+			pending <- p.load(index)
+		}(p.index)
+		p.pending = pending
+	}
+	if res.n == 0 {
+		return nil, false
+	}
+	return res.items, true
+}
+
+func (p *pager) load(index int) pageResult {
+	items, n, err := p.fetch(p.ctx, index, p.size)
+	return pageResult{items: items, n: n, err: err}
+}
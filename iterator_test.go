@@ -0,0 +1,80 @@
+package foxyproxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// newPagingServer serves total nodes and accounts, counting the requests it receives.
+func newPagingServer(total int) (*httptest.Server, *int32) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		index, _ := strconv.Atoi(r.URL.Query().Get("index"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+		items := []map[string]interface{}{}
+		for i := index; i < index+size && i < total; i++ {
+			name := fmt.Sprintf("item-%03d", i)
+			items = append(items, map[string]interface{}{"name": name, "username": name})
+		}
+		json.NewEncoder(w).Encode(items)
+	}))
+	return ts, &requests
+}
+
+func TestNodeIterator(t *testing.T) {
+	tests := []struct {
+		total    int
+		params   *IteratorParams
+		requests int32
+	}{
+		{0, nil, 1},
+		{250, nil, 3},
+		{200, nil, 3},
+		{250, &IteratorParams{PageSize: 50, Prefetch: true}, 6},
+	}
+	for _, test := range tests {
+		ts, requests := newPagingServer(test.total)
+		c := newTestClient(t, &NewClientParams{EndpointBaseURL: ts.URL})
+		it := c.Nodes(context.Background(), test.params)
+		count := 0
+		for it.Next() {
+			if name := fmt.Sprintf("item-%03d", count); it.Node().Name != name {
+				t.Errorf("expected node: %s, got %s", name, it.Node().Name)
+			}
+			count++
+		}
+		if err := it.Err(); err != nil {
+			t.Error(err)
+		}
+		if count != test.total {
+			t.Errorf("expected nodes: %d, got %d", test.total, count)
+		}
+		if *requests != test.requests {
+			t.Errorf("expected requests: %d, got %d", test.requests, *requests)
+		}
+		ts.Close()
+	}
+}
+
+func TestAccountIteratorError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+	c := newTestClient(t, &NewClientParams{EndpointBaseURL: ts.URL})
+	it := c.AccountsByUsername(context.Background(), "john", nil)
+	if it.Next() {
+		t.Error("expected iteration to stop")
+	}
+	if !errors.Is(it.Err(), ErrServer) {
+		t.Errorf("expected error: %v, got %v", ErrServer, it.Err())
+	}
+}
@@ -0,0 +1,155 @@
+package foxyproxy
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// redacted replaces secrets in logged requests.
+const redacted = "[REDACTED]"
+
+// redactedHeaders lists the request headers whose values are never logged.
+var redactedHeaders = []string{"Authorization", "X-Domain"}
+
+// redactedFields lists the JSON request body fields whose values are never logged.
+var redactedFields = map[string]bool{"password": true}
+
+// redactedParams lists the endpoint parameters whose values are never logged.
+var redactedParams = map[string]bool{"username": true}
+
+// logAttempt logs a request attempt at debug level, redacting credentials. retry reports whether
+// the attempt is retried after wait.
+func (c *Client) logAttempt(ctx context.Context, req *Request, attempt int, latency time.Duration, res *http.Response, err error, retry bool, wait time.Duration) {
+	if c.logger == nil || !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("path", logPath(req)),
+		slog.Int("attempt", attempt),
+		slog.Duration("latency", latency),
+	}
+	if res != nil {
+		attrs = append(attrs, slog.Int("status", res.StatusCode))
+		if res.Request != nil {
+			attrs = append(attrs, slog.Any("header", logHeader(res.Request.Header)))
+		}
+	}
+	if len(req.Body) > 0 {
+		attrs = append(attrs, slog.Any("body", logBody(req.Body)))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", logError(req, err)))
+	}
+	msg := "foxyproxy request"
+	if retry {
+		msg = "foxyproxy request retry"
+		attrs = append(attrs, slog.Duration("wait", wait))
+	}
+	c.logger.LogAttrs(ctx, slog.LevelDebug, msg, attrs...)
+}
+
+// logPath returns the path of req with the values of redacted parameters, e.g. usernames,
+// replaced.
+func logPath(req *Request) string {
+	if req.Endpoint == "" {
+		return req.Path
+	}
+	var path strings.Builder
+	rest := req.Endpoint
+	for {
+		start := strings.IndexByte(rest, '{')
+		end := strings.IndexByte(rest, '}')
+		if start < 0 || end < start {
+			break
+		}
+		name := rest[start+1 : end]
+		value := req.Params[name]
+		if redactedParams[name] {
+			value = redacted
+		}
+		path.WriteString(rest[:start])
+		path.WriteString(value)
+		rest = rest[end+1:]
+	}
+	path.WriteString(rest)
+	return path.String()
+}
+
+// logError returns the message of err with the path of req, which errors include, redacted as by
+// logPath.
+func logError(req *Request, err error) string {
+	msg := err.Error()
+	if req.Path == "" {
+		return msg
+	}
+	path := logPath(req)
+	msg = strings.ReplaceAll(msg, req.Path, path)
+	if i := strings.IndexByte(req.Path, '?'); i >= 0 {
+		if j := strings.IndexByte(path, '?'); j >= 0 {
+			msg = strings.ReplaceAll(msg, req.Path[:i], path[:j])
+		}
+	}
+	return msg
+}
+
+// logHeader is a request header which redacts credentials when logged.
+type logHeader http.Header
+
+// LogValue implements slog.LogValuer.
+func (h logHeader) LogValue() slog.Value {
+	header := http.Header(h).Clone()
+	for _, key := range redactedHeaders {
+		if header.Get(key) != "" {
+			header.Set(key, redacted)
+		}
+	}
+	attrs := make([]slog.Attr, 0, len(header))
+	for key, values := range header {
+		if len(values) == 1 {
+			attrs = append(attrs, slog.String(key, values[0]))
+		} else {
+			attrs = append(attrs, slog.Any(key, values))
+		}
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// logBody is a JSON request body which redacts secret fields when logged.
+type logBody []byte
+
+// LogValue implements slog.LogValuer.
+func (b logBody) LogValue() slog.Value {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		// never log a body which cannot be inspected
+		return slog.StringValue(redacted)
+	}
+	body, err := json.Marshal(redactFields(v))
+	if err != nil {
+		return slog.StringValue(redacted)
+	}
+	return slog.StringValue(string(body))
+}
+
+func redactFields(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if redactedFields[key] {
+				v[key] = redacted
+			} else {
+				v[key] = redactFields(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = redactFields(value)
+		}
+	}
+	return v
+}
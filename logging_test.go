@@ -0,0 +1,64 @@
+package foxyproxy
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoggerRedaction(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"count":1}`))
+	}))
+	defer ts.Close()
+	var logs bytes.Buffer
+	c := newTestClient(t, &NewClientParams{
+		Username:        "admin",
+		Password:        "admin-secret",
+		DomainHeader:    "domain-secret",
+		EndpointBaseURL: ts.URL,
+		RetryPolicy:     &RetryPolicy{MaxAttempts: 2, Methods: []string{http.MethodPatch}},
+		Logger:          slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+
+	if _, err := c.UpdatePassword("john", "customer-secret"); err != nil {
+		t.Fatal(err)
+	}
+	out := logs.String()
+	for _, secret := range []string{"admin-secret", "domain-secret", "customer-secret", "Basic ", "john"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("expected %q to be redacted, got %s", secret, out)
+		}
+	}
+	for _, expected := range []string{
+		`msg="foxyproxy request retry"`, "status=429", "attempt=1", "wait=0s",
+		`msg="foxyproxy request"`, "status=200", "path=/accounts/update-password/[REDACTED]", "attempt=2", "method=PATCH", "latency=", "[REDACTED]",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in logs, got %s", expected, out)
+		}
+	}
+}
+
+func TestLogError(t *testing.T) {
+	req := endpointGetAccountsByUsername.request("john", 0, 10)
+	err := &RequestError{Method: req.Method, Path: req.Path, Err: errors.New("boom")}
+	if msg := logError(req, err); msg != "foxyproxy: GET /accounts/[REDACTED]/?index=0&size=10: boom" {
+		t.Errorf("unexpected error message: %s", msg)
+	}
+	apiErr := &Error{Status: http.StatusNotFound, Path: "/accounts/john/"}
+	if msg := logError(req, apiErr); strings.Contains(msg, "john") {
+		t.Errorf("expected the username to be redacted, got %s", msg)
+	}
+}
@@ -0,0 +1,158 @@
+package foxyproxy
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Request represents an api request passing through the client's middleware chain.
+type Request struct {
+	// Operation is the name of the client method performing the request, without the Context
+	// suffix, e.g. GetNodeTrafficTotals.
+	Operation string
+	Method    string
+	// Path is the path of the request, relative to the endpoint base url.
+	Path string
+	// Endpoint is the template of Path, e.g. /nodes/{name}/traffic/{start}/{end}. Unlike Path, it
+	// doesn't vary with the parameters of the request.
+	Endpoint string
+	// Params holds the values of the parameters of Endpoint, by name.
+	Params map[string]string
+	// Header holds additional request headers. The authorization, Accept, Content-Type and
+	// X-DOMAIN headers are set by the client, and overridden by the ones set here.
+	Header http.Header
+	// Body is the JSON request body, or nil.
+	Body []byte
+	// Result is a pointer to the value the JSON response body is decoded into, or nil if the
+	// response body is ignored. Once Do returns successfully, it holds the decoded response.
+	Result interface{}
+}
+
+// Response represents a successful api response.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	// Body is the raw response body.
+	Body []byte
+}
+
+// Doer performs api requests. The Doer at the end of the middleware chain sends the request,
+// retrying it according to the client's retry policy, and decodes the response into req.Result.
+// Responses with an unexpected status are returned as *Error, other failures as *RequestError.
+type Doer interface {
+	Do(ctx context.Context, req *Request) (*Response, error)
+}
+
+// DoerFunc is an adapter to allow the use of ordinary functions as Doers.
+type DoerFunc func(ctx context.Context, req *Request) (*Response, error)
+
+// Do calls f(ctx, req).
+func (f DoerFunc) Do(ctx context.Context, req *Request) (*Response, error) {
+	return f(ctx, req)
+}
+
+// Middleware wraps a Doer to run code around every request, such as auditing, header mutation or
+// request signing.
+type Middleware func(next Doer) Doer
+
+// Use adds middlewares to the client. Middlewares run in the order they were added, so the first
+// one sees the request first and the response last. Use must not be called concurrently with
+// requests.
+func (c *Client) Use(middlewares ...Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+	var doer Doer = DoerFunc(c.send)
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		doer = c.middlewares[i](doer)
+	}
+	c.doer = doer
+}
+
+// LoggingMiddleware returns a middleware which logs every request with its status and duration,
+// or its error, to logger at info level. If logger is nil, slog.Default() is used. Credentials, the
+// X-DOMAIN header, passwords and the usernames of request paths are redacted as in the client's own
+// debug logs (see NewClientParams.Logger).
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, req *Request) (*Response, error) {
+			start := time.Now()
+			res, err := next.Do(ctx, req)
+			l := logger
+			if l == nil {
+				l = slog.Default()
+			}
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("path", logPath(req)),
+				slog.Duration("latency", time.Since(start)),
+			}
+			if len(req.Header) > 0 {
+				attrs = append(attrs, slog.Any("header", logHeader(req.Header)))
+			}
+			if len(req.Body) > 0 {
+				attrs = append(attrs, slog.Any("body", logBody(req.Body)))
+			}
+			var apiError *Error
+			switch {
+			case errors.As(err, &apiError):
+				attrs = append(attrs, slog.Int("status", apiError.Status), slog.String("error", apiError.Message))
+			case err != nil:
+				attrs = append(attrs, slog.String("error", logError(req, err)))
+			default:
+				attrs = append(attrs, slog.Int("status", res.StatusCode))
+			}
+			level := slog.LevelInfo
+			if err != nil {
+				level = slog.LevelError
+			}
+			l.LogAttrs(ctx, level, "foxyproxy request", attrs...)
+			return res, err
+		})
+	}
+}
+
+// RequestIDHeader is the header used by RequestIDMiddleware to propagate request IDs.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the specified request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// RequestIDMiddleware returns a middleware which sends the request ID carried by the request
+// context (see WithRequestID) in the X-Request-ID header. Requests without one get a random ID,
+// which is also visible to the next middlewares through the context. Retries of a request share
+// its ID.
+func RequestIDMiddleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, req *Request) (*Response, error) {
+			id, ok := RequestIDFromContext(ctx)
+			if !ok {
+				id = newRequestID()
+				ctx = WithRequestID(ctx, id)
+			}
+			req.Header.Set(RequestIDHeader, id)
+			return next.Do(ctx, req)
+		})
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
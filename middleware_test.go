@@ -0,0 +1,100 @@
+package foxyproxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var requestID string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = r.Header.Get(RequestIDHeader)
+		if r.URL.Path == "/accounts/count/" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"status":500,"message":"boom"}`))
+			return
+		}
+		w.Write([]byte(`{"count":3}`))
+	}))
+	defer ts.Close()
+	c := newTestClient(t, &NewClientParams{EndpointBaseURL: ts.URL})
+
+	calls := []string{}
+	trace := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(ctx context.Context, req *Request) (*Response, error) {
+				calls = append(calls, name+" "+req.Method+" "+req.Path)
+				res, err := next.Do(ctx, req)
+				if err == nil {
+					calls = append(calls, name+" "+string(res.Body))
+				}
+				return res, err
+			})
+		}
+	}
+	var logs bytes.Buffer
+	c.Use(trace("outer"), RequestIDMiddleware())
+	c.Use(trace("inner"), LoggingMiddleware(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	if count, err := c.GetNodeCountContext(WithRequestID(context.Background(), "abc")); err != nil || count != 3 {
+		t.Fatalf("expected node count: 3, got (%d, %v)", count, err)
+	}
+	if requestID != "abc" {
+		t.Errorf("expected request id: %q, got %q", "abc", requestID)
+	}
+	expected := []string{
+		"outer GET /nodes/count/",
+		"inner GET /nodes/count/",
+		`inner {"count":3}`,
+		`outer {"count":3}`,
+	}
+	if strings.Join(calls, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected calls: %q, got %q", expected, calls)
+	}
+
+	_, err := c.CountAccounts()
+	var apiError *Error
+	if !errors.As(err, &apiError) || apiError.Status != http.StatusInternalServerError {
+		t.Errorf("expected *Error with status 500, got %v", err)
+	}
+	if len(requestID) != 32 {
+		t.Errorf("expected a generated request id, got %q", requestID)
+	}
+	for _, expected := range []string{
+		`level=INFO msg="foxyproxy request" method=GET path=/nodes/count/ latency=`,
+		"status=200",
+		`level=ERROR msg="foxyproxy request" method=GET path=/accounts/count/`,
+		"status=500 error=boom",
+		"header.X-Request-Id=abc",
+	} {
+		if !strings.Contains(logs.String(), expected) {
+			t.Errorf("expected %q in logs, got %s", expected, logs.String())
+		}
+	}
+}
+
+func TestLoggingMiddlewareRedaction(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"count":1}`))
+	}))
+	defer ts.Close()
+	c := newTestClient(t, &NewClientParams{EndpointBaseURL: ts.URL})
+	var logs bytes.Buffer
+	c.Use(LoggingMiddleware(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	if _, err := c.UpdatePassword("john", "customer-secret"); err != nil {
+		t.Fatal(err)
+	}
+	if out := logs.String(); strings.Contains(out, "customer-secret") || !strings.Contains(out, "[REDACTED]") {
+		t.Errorf("expected the password to be redacted, got %s", out)
+	}
+	if out := logs.String(); strings.Contains(out, "john") {
+		t.Errorf("expected the username to be redacted, got %s", out)
+	}
+}
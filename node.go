@@ -1,6 +1,9 @@
 package foxyproxy
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -14,11 +17,11 @@ type Node struct {
 	City        string
 	Services    []*NodeService
 
-	client *Client
+	client API
 }
 
 // NewNode generates a new node object.
-func NewNode(c *Client) *Node {
+func NewNode(c API) *Node {
 	return &Node{
 		client: c,
 	}
@@ -28,45 +31,136 @@ func NewNode(c *Client) *Node {
 // closed connections.
 // See https://reseller.api.foxyproxy.com/#_active_node_connections_by_account.
 func (n *Node) GetActiveConnectionsByAccount() ([]*NodeConnection, error) {
-	return n.client.getActiveNodeConnectionsByAccount(n.Name)
+	return n.GetActiveConnectionsByAccountContext(context.Background())
+}
+
+// GetActiveConnectionsByAccountContext is like GetActiveConnectionsByAccount but uses ctx for the
+// lifetime of the request.
+func (n *Node) GetActiveConnectionsByAccountContext(ctx context.Context) ([]*NodeConnection, error) {
+	return n.client.GetActiveNodeConnectionsByAccountContext(ctx, n.Name)
 }
 
 // GetActiveConnectionTotals gets a count of active connections for the node.
 // See https://reseller.api.foxyproxy.com/#_active_node_connection_totals.
 func (n *Node) GetActiveConnectionTotals() (int, error) {
-	return n.client.getActiveNodeConnectionTotals(n.Name)
+	return n.GetActiveConnectionTotalsContext(context.Background())
+}
+
+// GetActiveConnectionTotalsContext is like GetActiveConnectionTotals but uses ctx for the lifetime
+// of the request.
+func (n *Node) GetActiveConnectionTotalsContext(ctx context.Context) (int, error) {
+	return n.client.GetActiveNodeConnectionTotalsContext(ctx, n.Name)
 }
 
 // GetHistoricalConnectionsByAccount gets the connections for the node between startTime and
 // endTime, inclusive. This does not include active connections.
 // See https://reseller.api.foxyproxy.com/#_historical_node_connections_by_account.
 func (n *Node) GetHistoricalConnectionsByAccount(startTime, endTime time.Time) ([]*NodeConnection, error) {
-	return n.client.getHistoricalNodeConnectionsByAccount(n.Name, startTime, endTime)
+	return n.GetHistoricalConnectionsByAccountContext(context.Background(), startTime, endTime)
+}
+
+// GetHistoricalConnectionsByAccountContext is like GetHistoricalConnectionsByAccount but uses ctx
+// for the lifetime of the request.
+func (n *Node) GetHistoricalConnectionsByAccountContext(ctx context.Context, startTime, endTime time.Time) ([]*NodeConnection, error) {
+	return n.client.GetHistoricalNodeConnectionsByAccountContext(ctx, n.Name, startTime, endTime)
 }
 
 // GetHistoricalConnectionTotals gets a count of connections for the node between startTime and
 // endtTime, inclusive. This does not include active connections.
 // See https://reseller.api.foxyproxy.com/#_historical_node_connection_totals.
 func (n *Node) GetHistoricalConnectionTotals(startTime, endTime time.Time) (int, error) {
-	return n.client.getHistoricalNodeConnectionTotals(n.Name, startTime, endTime)
+	return n.GetHistoricalConnectionTotalsContext(context.Background(), startTime, endTime)
+}
+
+// GetHistoricalConnectionTotalsContext is like GetHistoricalConnectionTotals but uses ctx for the
+// lifetime of the request.
+func (n *Node) GetHistoricalConnectionTotalsContext(ctx context.Context, startTime, endTime time.Time) (int, error) {
+	return n.client.GetHistoricalNodeConnectionTotalsContext(ctx, n.Name, startTime, endTime)
 }
 
 // GetTrafficByAccount gets various traffic counts and last authentication info for all accounts on
 // the node between two startTime and endTime, inclusive.
 // See https://reseller.api.foxyproxy.com/#_node_traffic_by_account.
 func (n *Node) GetTrafficByAccount(startTime, endTime time.Time) ([]*NodeTrafficAccount, error) {
-	return n.client.getNodeTrafficByAccount(n.Name, startTime, endTime)
+	return n.GetTrafficByAccountContext(context.Background(), startTime, endTime)
+}
+
+// GetTrafficByAccountContext is like GetTrafficByAccount but uses ctx for the lifetime of the
+// request.
+func (n *Node) GetTrafficByAccountContext(ctx context.Context, startTime, endTime time.Time) ([]*NodeTrafficAccount, error) {
+	return n.client.GetNodeTrafficByAccountContext(ctx, n.Name, startTime, endTime)
 }
 
 // GetTrafficTotals gets various traffic counts for the node between startTime and endTime,
 // inclusive.
 // See https://reseller.api.foxyproxy.com/#_node_traffic_totals.
 func (n *Node) GetTrafficTotals(startTime, endTime time.Time) (*NodeTrafficTotals, error) {
-	return n.client.getNodeTrafficTotals(n.Name, startTime, endTime)
+	return n.GetTrafficTotalsContext(context.Background(), startTime, endTime)
+}
+
+// GetTrafficTotalsContext is like GetTrafficTotals but uses ctx for the lifetime of the request.
+func (n *Node) GetTrafficTotalsContext(ctx context.Context, startTime, endTime time.Time) (*NodeTrafficTotals, error) {
+	return n.client.GetNodeTrafficTotalsContext(ctx, n.Name, startTime, endTime)
 }
 
 // GetAccountsByNode gets all accounts for the node.
 // See https://reseller.api.foxyproxy.com/#_get_accounts_by_node.
 func (n *Node) GetAccountsByNode(index, size int) ([]*Account, error) {
-	return n.client.getAccountsByNode(n.Name, index, size)
+	return n.GetAccountsByNodeContext(context.Background(), index, size)
+}
+
+// GetAccountsByNodeContext is like GetAccountsByNode but uses ctx for the lifetime of the request.
+func (n *Node) GetAccountsByNodeContext(ctx context.Context, index, size int) ([]*Account, error) {
+	return n.client.GetAccountsByNodeContext(ctx, n.Name, index, size)
+}
+
+// CreateAccount creates an account with the specified username and password on the node.
+// See https://reseller.api.foxyproxy.com/#_create_accounts.
+func (n *Node) CreateAccount(username, password string) (*Account, error) {
+	return n.CreateAccountContext(context.Background(), username, password)
+}
+
+// CreateAccountContext is like CreateAccount but uses ctx for the lifetime of the request.
+func (n *Node) CreateAccountContext(ctx context.Context, username, password string) (*Account, error) {
+	accounts, err := n.client.CreateAccountsContext(ctx, username, password, &CommonProperties{
+		NodeNames: []string{n.Name},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("foxyproxy: no account created on node %q", n.Name)
+	}
+	return accounts[0], nil
+}
+
+// Hostnames gets the DNS suffixes of the reseller pool and returns the hostnames of the node, one
+// per suffix. When listing hostnames for many nodes, get the suffixes once with
+// Client.GetDNSSuffixes and use HostnamesWithSuffixes instead.
+// See https://reseller.api.foxyproxy.com/#_get_dns_suffixes.
+func (n *Node) Hostnames() ([]string, error) {
+	return n.HostnamesContext(context.Background())
+}
+
+// HostnamesContext is like Hostnames but uses ctx for the lifetime of the request.
+func (n *Node) HostnamesContext(ctx context.Context) ([]string, error) {
+	suffixes, err := n.client.GetDNSSuffixesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return n.HostnamesWithSuffixes(suffixes), nil
+}
+
+// HostnamesWithSuffixes returns the hostnames of the node, combining its name with each one of the
+// specified DNS suffixes.
+func (n *Node) HostnamesWithSuffixes(suffixes []string) []string {
+	hostnames := make([]string, 0, len(suffixes))
+	for _, suffix := range suffixes {
+		suffix = strings.Trim(suffix, ".")
+		if suffix == "" {
+			continue
+		}
+		hostnames = append(hostnames, fmt.Sprintf("%s.%s", n.Name, suffix))
+	}
+	return hostnames
 }
@@ -1,10 +1,7 @@
 package foxyproxy
 
 import (
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
+	"context"
 	"time"
 )
 
@@ -16,35 +13,18 @@ type NodeConnection struct {
 	Connections int
 }
 
-func (c *Client) getActiveNodeConnectionsByAccount(nodeName string) ([]*NodeConnection, error) {
-	res, err := c.doRequest(http.MethodGet, fmt.Sprintf("/nodes/%s/connections-by-account/", nodeName), nil)
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
-	fmt.Println(string(bodyBytes))
+func (c *Client) getActiveNodeConnectionsByAccount(ctx context.Context, nodeName string) ([]*NodeConnection, error) {
 	connections := []*NodeConnection{}
-	if err := json.Unmarshal(bodyBytes, &connections); err != nil {
-		return nil, err
+	if err := c.doJSON(ctx, endpointGetActiveNodeConnectionsByAccount.request(nodeName), nil, &connections); err != nil {
+		return nil, notFound(err, ResourceNode, nodeName)
 	}
 	return connections, nil
 }
 
-func (c *Client) getHistoricalNodeConnectionsByAccount(nodeName string, startTime, endTime time.Time) ([]*NodeConnection, error) {
-	res, err := c.doRequest(http.MethodGet, fmt.Sprintf("/nodes/%s/connections-by-account/%d/%d/", nodeName, startTime.Unix(), endTime.Unix()), nil)
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) getHistoricalNodeConnectionsByAccount(ctx context.Context, nodeName string, startTime, endTime time.Time) ([]*NodeConnection, error) {
 	connections := []*NodeConnection{}
-	if err := json.Unmarshal(bodyBytes, &connections); err != nil {
-		return nil, err
+	if err := c.doJSON(ctx, endpointGetHistoricalNodeConnectionsByAccount.request(nodeName, startTime, endTime), nil, &connections); err != nil {
+		return nil, notFound(err, ResourceNode, nodeName)
 	}
 	return connections, nil
 }
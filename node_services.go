@@ -1,5 +1,10 @@
 package foxyproxy
 
+import (
+	"net"
+	"strconv"
+)
+
 // NodeService is a node service.
 // See https://reseller.api.foxyproxy.com/#_available_services.
 type NodeService struct {
@@ -7,3 +12,15 @@ type NodeService struct {
 	Name   string `json:"name"`
 	Ports  []int  `json:"ports,omitempty"`
 }
+
+// Endpoints returns the "host:port" endpoints of the service, combining each one of the specified
+// hostnames with each one of the service's ports. See Node.Hostnames.
+func (s *NodeService) Endpoints(hostnames []string) []string {
+	endpoints := make([]string, 0, len(hostnames)*len(s.Ports))
+	for _, hostname := range hostnames {
+		for _, port := range s.Ports {
+			endpoints = append(endpoints, net.JoinHostPort(hostname, strconv.Itoa(port)))
+		}
+	}
+	return endpoints
+}
@@ -0,0 +1,37 @@
+package foxyproxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestNodeHostnames(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/nodes/dns-suffixes/" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`["example-vpn.com", ".example-proxy.net", ""]`))
+	}))
+	defer ts.Close()
+	c := newTestClient(t, &NewClientParams{EndpointBaseURL: ts.URL})
+	node := NewNode(c)
+	node.Name = "us-nyc-1"
+
+	hostnames, err := node.Hostnames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"us-nyc-1.example-vpn.com", "us-nyc-1.example-proxy.net"}
+	if !reflect.DeepEqual(hostnames, expected) {
+		t.Errorf("expected hostnames: %v, got %v", expected, hostnames)
+	}
+
+	service := &NodeService{Name: "openvpn", Ports: []int{443, 1194}}
+	endpoints := service.Endpoints(hostnames[:1])
+	expected = []string{"us-nyc-1.example-vpn.com:443", "us-nyc-1.example-vpn.com:1194"}
+	if !reflect.DeepEqual(endpoints, expected) {
+		t.Errorf("expected endpoints: %v, got %v", expected, endpoints)
+	}
+}
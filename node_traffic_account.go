@@ -1,10 +1,8 @@
 package foxyproxy
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
 	"time"
 )
 
@@ -13,26 +11,26 @@ type NodeTrafficAccount struct {
 	UID         string
 	Active      bool
 	Username    string
-	TrafficDown float64
-	TrafficUp   float64
-	TrafficAll  float64
+	TrafficDown ByteSize
+	TrafficUp   ByteSize
+	TrafficAll  ByteSize
+	// Raw holds the traffic values as returned by the api, before they are rounded to whole bytes.
+	Raw RawTraffic `json:"-"`
 }
 
-func (c *Client) getNodeTrafficByAccount(nodeName string, startTime, endTime time.Time) ([]*NodeTrafficAccount, error) {
-	res, err := c.doRequest(http.MethodGet, fmt.Sprintf("/nodes/%s/traffic-by-account/%d/%d", nodeName, startTime.Unix(), endTime.Unix()), nil)
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(string(bodyBytes))
+// UnmarshalJSON decodes the traffic values of the api into ByteSize values and Raw.
+func (t *NodeTrafficAccount) UnmarshalJSON(data []byte) error {
+	type plain NodeTrafficAccount
+	if err := json.Unmarshal(data, (*plain)(t)); err != nil {
+		return err
 	}
+	return json.Unmarshal(data, &t.Raw)
+}
+
+func (c *Client) getNodeTrafficByAccount(ctx context.Context, nodeName string, startTime, endTime time.Time) ([]*NodeTrafficAccount, error) {
 	traffics := []*NodeTrafficAccount{}
-	if err := json.Unmarshal(bodyBytes, &traffics); err != nil {
-		return nil, err
+	if err := c.doJSON(ctx, endpointGetNodeTrafficByAccount.request(nodeName, startTime, endTime), nil, &traffics); err != nil {
+		return nil, notFound(err, ResourceNode, nodeName)
 	}
 	return traffics, nil
 }
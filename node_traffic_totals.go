@@ -1,36 +1,34 @@
 package foxyproxy
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
 	"time"
 )
 
 // NodeTrafficTotals is a total traffic count for a node.
 type NodeTrafficTotals struct {
-	TrafficDown float64
-	TrafficUp   float64
-	TrafficAll  float64
-	Quota       float64
+	TrafficDown ByteSize
+	TrafficUp   ByteSize
+	TrafficAll  ByteSize
+	Quota       ByteSize
+	// Raw holds the traffic values as returned by the api, before they are rounded to whole bytes.
+	Raw RawTraffic `json:"-"`
 }
 
-func (c *Client) getNodeTrafficTotals(nodeName string, startTime, endTime time.Time) (*NodeTrafficTotals, error) {
-	res, err := c.doRequest(http.MethodGet, fmt.Sprintf("/nodes/%s/traffic/%d/%d", nodeName, startTime.Unix(), endTime.Unix()), nil)
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(string(bodyBytes))
+// UnmarshalJSON decodes the traffic values of the api into ByteSize values and Raw.
+func (t *NodeTrafficTotals) UnmarshalJSON(data []byte) error {
+	type plain NodeTrafficTotals
+	if err := json.Unmarshal(data, (*plain)(t)); err != nil {
+		return err
 	}
+	return json.Unmarshal(data, &t.Raw)
+}
+
+func (c *Client) getNodeTrafficTotals(ctx context.Context, nodeName string, startTime, endTime time.Time) (*NodeTrafficTotals, error) {
 	traffic := &NodeTrafficTotals{}
-	if err := json.Unmarshal(bodyBytes, traffic); err != nil {
-		return nil, err
+	if err := c.doJSON(ctx, endpointGetNodeTrafficTotals.request(nodeName, startTime, endTime), nil, traffic); err != nil {
+		return nil, notFound(err, ResourceNode, nodeName)
 	}
 	return traffic, nil
 }
@@ -0,0 +1,201 @@
+package foxyproxy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProfile is the name of the profile used when none is specified.
+const DefaultProfile = "default"
+
+// Config is a set of named profiles, one per reseller domain, loaded from a YAML or TOML file:
+//
+//	profiles:
+//	  brand-a:
+//	    username: admin
+//	    password: secret
+//	    domain: brand-a
+//	    endpoint: https://reseller.brand-a.api.foxyproxy.com
+//	    timeout: 30s
+type Config struct {
+	Profiles map[string]*Profile `yaml:"profiles" toml:"profiles"`
+}
+
+// Profile holds the parameters of a client for a reseller domain. Each field can be overridden by
+// the environment variable FOXYPROXY_<PROFILE>_<FIELD>, e.g. FOXYPROXY_BRAND_A_PASSWORD for the
+// password of profile brand-a, where the profile name is upper-cased and other characters than
+// letters and digits are replaced with underscores.
+type Profile struct {
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+	// Domain is the X-DOMAIN header value.
+	Domain string `yaml:"domain" toml:"domain"`
+	// Endpoint is the endpoint base URL.
+	Endpoint string `yaml:"endpoint" toml:"endpoint"`
+	// Timeout is the time limit for each request, e.g. 30s.
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"`
+}
+
+// DefaultConfigPath returns the path of the config file read by NewClientFromProfile: the value of
+// the FOXYPROXY_CONFIG environment variable, or foxyproxy/config.yaml in the user config directory
+// (see os.UserConfigDir).
+func DefaultConfigPath() (string, error) {
+	if path := os.Getenv("FOXYPROXY_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "foxyproxy", "config.yaml"), nil
+}
+
+// LoadConfig reads the config file at path. Files with a .toml extension are decoded as TOML, all
+// others as YAML.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format := "yaml"
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		format = "toml"
+	}
+	config, err := ParseConfig(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// ParseConfig decodes a config in the specified format, "yaml" or "toml".
+func ParseConfig(data []byte, format string) (*Config, error) {
+	config := &Config{}
+	switch format {
+	case "yaml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("foxyproxy: invalid config: %w", err)
+		}
+	case "toml":
+		md, err := toml.Decode(string(data), config)
+		if err != nil {
+			return nil, fmt.Errorf("foxyproxy: invalid config: %w", err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("foxyproxy: invalid config: unknown key %q", undecoded[0].String())
+		}
+	default:
+		return nil, fmt.Errorf("foxyproxy: unknown config format %q", format)
+	}
+	return config, nil
+}
+
+// Names returns the sorted names of the profiles.
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Params returns the parameters of a client for the profile name, with the environment overrides
+// applied. If name is empty, the profile named by the FOXYPROXY_PROFILE environment variable, or
+// else DefaultProfile, is used.
+func (c *Config) Params(name string) (*NewClientParams, error) {
+	name = profileName(name)
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("foxyproxy: unknown profile %q", name)
+	}
+	// a profile without fields, e.g. a YAML key without a body, takes all its values from the
+	// environment
+	p := Profile{}
+	if profile != nil {
+		p = *profile
+	}
+	prefix := profileEnvPrefix(name)
+	for _, field := range []struct {
+		key   string
+		value *string
+	}{
+		{"USERNAME", &p.Username},
+		{"PASSWORD", &p.Password},
+		{"DOMAIN", &p.Domain},
+		{"ENDPOINT", &p.Endpoint},
+	} {
+		if v, ok := os.LookupEnv(prefix + field.key); ok {
+			*field.value = v
+		}
+	}
+	if v, ok := os.LookupEnv(prefix + "TIMEOUT"); ok {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("foxyproxy: invalid %sTIMEOUT: %w", prefix, err)
+		}
+		p.Timeout = timeout
+	}
+	return &NewClientParams{
+		Username:        p.Username,
+		Password:        p.Password,
+		DomainHeader:    p.Domain,
+		EndpointBaseURL: p.Endpoint,
+		Timeout:         p.Timeout,
+	}, nil
+}
+
+func profileName(name string) string {
+	if name == "" {
+		name = os.Getenv("FOXYPROXY_PROFILE")
+	}
+	if name == "" {
+		name = DefaultProfile
+	}
+	return name
+}
+
+// profileEnvPrefix returns the prefix of the environment variables overriding the profile name.
+func profileEnvPrefix(name string) string {
+	return "FOXYPROXY_" + strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return '_'
+		}
+		return unicode.ToUpper(r)
+	}, name) + "_"
+}
+
+// NewClientFromProfile generates a new client with the parameters of the profile name in the
+// config file at DefaultConfigPath. See Config.Params.
+func NewClientFromProfile(name string) (*Client, error) {
+	path, err := DefaultConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	params, err := config.Params(name)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewClient(params)
+	if err != nil {
+		return nil, fmt.Errorf("%w (profile %q)", err, profileName(name))
+	}
+	return c, nil
+}
@@ -0,0 +1,132 @@
+package foxyproxy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	yamlConfig = `
+profiles:
+  default:
+    username: admin
+    password: secret
+    domain: example-inc
+    endpoint: https://reseller.example-inc.api.foxyproxy.com
+  brand-b:
+    username: admin-b
+    domain: brand-b
+    endpoint: https://reseller.brand-b.api.foxyproxy.com
+    timeout: 30s
+`
+	tomlConfig = `
+[profiles.default]
+username = "admin"
+password = "secret"
+domain = "example-inc"
+endpoint = "https://reseller.example-inc.api.foxyproxy.com"
+
+[profiles.brand-b]
+username = "admin-b"
+domain = "brand-b"
+endpoint = "https://reseller.brand-b.api.foxyproxy.com"
+timeout = "30s"
+`
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Setenv("FOXYPROXY_PROFILE", "")
+	for format, data := range map[string]string{"yaml": yamlConfig, "toml": tomlConfig} {
+		config, err := ParseConfig([]byte(data), format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if names := strings.Join(config.Names(), ","); names != "brand-b,default" {
+			t.Errorf("%s: unexpected profiles: %s", format, names)
+		}
+		params, err := config.Params("")
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if params.Username != "admin" || params.Password != "secret" || params.DomainHeader != "example-inc" || params.EndpointBaseURL != "https://reseller.example-inc.api.foxyproxy.com" {
+			t.Errorf("%s: unexpected default params: %+v", format, params)
+		}
+		if params, err = config.Params("brand-b"); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if params.DomainHeader != "brand-b" || params.Timeout != 30*time.Second {
+			t.Errorf("%s: unexpected brand-b params: %+v", format, params)
+		}
+	}
+
+	if _, err := ParseConfig([]byte("profiles:\n  default:\n    user: admin\n"), "yaml"); err == nil {
+		t.Error("expected an error for an unknown yaml key")
+	}
+	if _, err := ParseConfig([]byte("[profiles.default]\nuser = \"admin\"\n"), "toml"); err == nil {
+		t.Error("expected an error for an unknown toml key")
+	}
+}
+
+func TestConfigParamsEmptyProfile(t *testing.T) {
+	t.Setenv("FOXYPROXY_DEFAULT_USERNAME", "admin")
+	config, err := ParseConfig([]byte("profiles:\n  default:\n"), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := config.Params("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Username != "admin" || params.Password != "" {
+		t.Errorf("unexpected params: %+v", params)
+	}
+}
+
+func TestConfigParamsEnv(t *testing.T) {
+	config, err := ParseConfig([]byte(yamlConfig), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FOXYPROXY_PROFILE", "brand-b")
+	t.Setenv("FOXYPROXY_BRAND_B_PASSWORD", "secret-b")
+	t.Setenv("FOXYPROXY_BRAND_B_TIMEOUT", "1m")
+	params, err := config.Params("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Username != "admin-b" || params.Password != "secret-b" || params.Timeout != time.Minute {
+		t.Errorf("unexpected params: %+v", params)
+	}
+	if config.Profiles["brand-b"].Password != "" {
+		t.Error("expected the profile to be left unchanged")
+	}
+	if _, err := config.Params("brand-c"); err == nil || !strings.Contains(err.Error(), `unknown profile "brand-c"`) {
+		t.Errorf("expected an unknown profile error, got %v", err)
+	}
+}
+
+func TestNewClientFromProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(tomlConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FOXYPROXY_CONFIG", path)
+	t.Setenv("FOXYPROXY_PROFILE", "")
+	t.Setenv("FOXYPROXY_BRAND_B_PASSWORD", "")
+
+	c, err := NewClientFromProfile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.domainHeader != "example-inc" {
+		t.Errorf("expected client domain header: %s, got %s", "example-inc", c.domainHeader)
+	}
+	_, err = NewClientFromProfile("brand-b")
+	if !errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), `missing client parameters: Password (profile "brand-b")`) {
+		t.Errorf("expected a missing password error, got %v", err)
+	}
+}
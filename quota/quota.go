@@ -0,0 +1,281 @@
+// Package quota tracks traffic usage against node quotas and per-account limits. An Engine emits a
+// warning for every node or account whose usage crosses one of the configured thresholds, and can
+// deactivate the accounts which exceed their limit:
+//
+//	engine := quota.New(client, &quota.Policy{
+//		AccountLimits: map[string]foxyproxy.ByteSize{"john": 50 * foxyproxy.GB},
+//		Enforce:       true,
+//		DryRun:        true,
+//	})
+//	result, err := engine.Check(ctx, monthStart, time.Now())
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"github.com/jsignanini/foxyproxy-reseller-go/internal/parallel"
+	"github.com/jsignanini/foxyproxy-reseller-go/report"
+)
+
+// DefaultThresholds are the thresholds used when a policy doesn't set any.
+var DefaultThresholds = []float64{0.8, 1}
+
+// Policy configures the limits checked by an engine.
+type Policy struct {
+	// Thresholds are the fractions of a quota or limit at which warnings are emitted, e.g. 0.8 for
+	// 80%. Defaults to DefaultThresholds.
+	Thresholds []float64
+	// AccountLimits are the traffic limits of accounts by username. The usage of an account is its
+	// TrafficAll across all nodes.
+	AccountLimits map[string]foxyproxy.ByteSize
+	// DefaultAccountLimit is the limit of accounts missing from AccountLimits. Zero means no
+	// limit.
+	DefaultAccountLimit foxyproxy.ByteSize
+	// Enforce deactivates, on all their nodes, the accounts whose usage reaches their limit. Node
+	// quotas are never enforced.
+	Enforce bool
+	// DryRun reports the deactivations Enforce would perform without performing them.
+	DryRun bool
+	// Comment returns the comment recorded on deactivated accounts. Defaults to a message with the
+	// usage and limit of the account.
+	Comment func(w *Warning) string
+	// OnWarning, if set, is called for every warning, as soon as it is emitted.
+	OnWarning func(w *Warning)
+	// Parallelism is the number of nodes queried, and of accounts deactivated, concurrently.
+	// Defaults to foxyproxy.DefaultParallelism.
+	Parallelism int
+}
+
+// Kind is the kind of limit a warning is about.
+type Kind int
+
+// Kinds of limits.
+const (
+	// NodeQuota is the traffic quota of a node, as returned by the api.
+	NodeQuota Kind = iota + 1
+	// AccountLimit is a limit configured in the policy.
+	AccountLimit
+)
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case NodeQuota:
+		return "node quota"
+	case AccountLimit:
+		return "account limit"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Warning is emitted when the usage of a node or an account crosses a threshold.
+type Warning struct {
+	Kind Kind
+	// NodeName is set for node quotas.
+	NodeName string
+	// Username is set for account limits.
+	Username string
+	// Usage and Limit are the traffic and the quota or limit.
+	Usage foxyproxy.ByteSize
+	Limit foxyproxy.ByteSize
+	// Threshold is the highest threshold crossed.
+	Threshold float64
+}
+
+// Ratio returns the fraction of the limit used.
+func (w *Warning) Ratio() float64 {
+	return float64(w.Usage) / float64(w.Limit)
+}
+
+// Exceeded reports whether the usage reached the limit.
+func (w *Warning) Exceeded() bool {
+	return w.Usage >= w.Limit
+}
+
+// String returns a string representation of the warning.
+func (w *Warning) String() string {
+	subject := "node " + w.NodeName
+	if w.Kind == AccountLimit {
+		subject = "account " + w.Username
+	}
+	return fmt.Sprintf("%s: %.0f%% of %s used (%s of %s)", subject, w.Ratio()*100, w.Kind, w.Usage, w.Limit)
+}
+
+// Deactivation is the deactivation of an account exceeding its limit.
+type Deactivation struct {
+	Warning *Warning
+	Comment string
+	// NodeNames are the nodes the account was active on.
+	NodeNames []string
+	// Count is the number of deactivated accounts, as returned by the api. It is zero in dry-run
+	// mode.
+	Count  int
+	DryRun bool
+	Err    error
+}
+
+// Result is the result of a check.
+type Result struct {
+	StartTime, EndTime time.Time
+	// Warnings are sorted by kind, then by node name or username.
+	Warnings      []*Warning
+	Deactivations []*Deactivation
+	// Errors holds the errors of the nodes whose usage could not be retrieved, by node name.
+	Errors map[string]error
+}
+
+// Engine checks traffic usage against a policy.
+type Engine struct {
+	api    foxyproxy.API
+	policy *Policy
+}
+
+// New generates a new engine checking the nodes and accounts retrieved through api.
+func New(api foxyproxy.API, policy *Policy) *Engine {
+	if policy == nil {
+		policy = &Policy{}
+	}
+	return &Engine{
+		api:    api,
+		policy: policy,
+	}
+}
+
+// Check compares the traffic of all nodes and accounts between startTime and endTime, inclusive,
+// against the policy, and deactivates the accounts exceeding their limit if the policy is
+// enforced. The returned error is only set if the nodes could not be listed or ctx is done.
+func (e *Engine) Check(ctx context.Context, startTime, endTime time.Time) (*Result, error) {
+	result := &Result{StartTime: startTime, EndTime: endTime, Errors: map[string]error{}}
+
+	totals, errs, err := foxyproxy.CollectFromNodes(ctx, e.api, e.policy.Parallelism, func(ctx context.Context, node *foxyproxy.Node) (*foxyproxy.NodeTrafficTotals, error) {
+		return node.GetTrafficTotalsContext(ctx, startTime, endTime)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for name, err := range errs {
+		result.Errors[name] = err
+	}
+	for name, t := range totals {
+		if w := e.check(&Warning{Kind: NodeQuota, NodeName: name, Usage: t.TrafficAll, Limit: t.Quota}); w != nil {
+			result.Warnings = append(result.Warnings, w)
+		}
+	}
+
+	if e.policy.DefaultAccountLimit > 0 || len(e.policy.AccountLimits) > 0 {
+		r, err := report.Generate(ctx, e.api, startTime, endTime, &report.Params{Parallelism: e.policy.Parallelism})
+		if err != nil {
+			return nil, err
+		}
+		for name, err := range r.Errors {
+			result.Errors[name] = err
+		}
+		for username, at := range r.ByUsername {
+			limit, ok := e.policy.AccountLimits[username]
+			if !ok {
+				limit = e.policy.DefaultAccountLimit
+			}
+			if w := e.check(&Warning{Kind: AccountLimit, Username: username, Usage: at.TrafficAll, Limit: limit}); w != nil {
+				result.Warnings = append(result.Warnings, w)
+			}
+		}
+	}
+
+	sort.Slice(result.Warnings, func(i, j int) bool {
+		wi, wj := result.Warnings[i], result.Warnings[j]
+		if wi.Kind != wj.Kind {
+			return wi.Kind < wj.Kind
+		}
+		return wi.NodeName+wi.Username < wj.NodeName+wj.Username
+	})
+	if e.policy.Enforce {
+		result.Deactivations = e.enforce(ctx, result.Warnings)
+	}
+	return result, ctx.Err()
+}
+
+// check fills in the threshold crossed by w and returns it, or nil if none was crossed.
+func (e *Engine) check(w *Warning) *Warning {
+	if w.Limit <= 0 {
+		return nil
+	}
+	thresholds := e.policy.Thresholds
+	if len(thresholds) == 0 {
+		thresholds = DefaultThresholds
+	}
+	for _, t := range thresholds {
+		if w.Ratio() >= t && t > w.Threshold {
+			w.Threshold = t
+		}
+	}
+	if w.Threshold == 0 {
+		return nil
+	}
+	if e.policy.OnWarning != nil {
+		e.policy.OnWarning(w)
+	}
+	return w
+}
+
+// enforce deactivates the active accounts exceeding their limit.
+func (e *Engine) enforce(ctx context.Context, warnings []*Warning) []*Deactivation {
+	deactivations := []*Deactivation{}
+	for _, w := range warnings {
+		if w.Kind != AccountLimit || !w.Exceeded() {
+			continue
+		}
+		deactivations = append(deactivations, &Deactivation{Warning: w, Comment: e.comment(w), DryRun: e.policy.DryRun})
+	}
+	parallelism := e.policy.Parallelism
+	if parallelism < 1 {
+		parallelism = foxyproxy.DefaultParallelism
+	}
+	err := parallel.ForEach(ctx, parallelism, deactivations, func(_ int, d *Deactivation) {
+		e.deactivate(ctx, d)
+	})
+	if err != nil {
+		for _, d := range deactivations {
+			if d.NodeNames == nil {
+				// never started
+				d.Err = err
+			}
+		}
+	}
+	return deactivations
+}
+
+func (e *Engine) deactivate(ctx context.Context, d *Deactivation) {
+	d.NodeNames = []string{}
+	it := e.api.AccountsByUsername(ctx, d.Warning.Username, nil)
+	for it.Next() {
+		a := it.Account()
+		if !a.Active {
+			continue
+		}
+		d.NodeNames = append(d.NodeNames, a.GetNodeNames()...)
+		if d.DryRun {
+			continue
+		}
+		count, err := a.DeactivateWithCommentContext(ctx, d.Comment)
+		d.Count += count
+		if err != nil {
+			d.Err = err
+			return
+		}
+	}
+	if err := it.Err(); err != nil {
+		d.Err = err
+	}
+}
+
+func (e *Engine) comment(w *Warning) string {
+	if e.policy.Comment != nil {
+		return e.policy.Comment(w)
+	}
+	return fmt.Sprintf("Suspended: traffic limit exceeded (%s of %s used)", w.Usage, w.Limit)
+}
@@ -0,0 +1,157 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"github.com/jsignanini/foxyproxy-reseller-go/foxyproxytest"
+)
+
+func newServer() *foxyproxytest.Server {
+	srv := foxyproxytest.NewServer()
+	srv.AddNode(&foxyproxy.Node{Name: "node-a"})
+	srv.AddNode(&foxyproxy.Node{Name: "node-b"})
+	srv.SetQuota("node-a", 1000)
+	srv.SetQuota("node-b", 1000)
+	for _, a := range []*foxyproxytest.AccountState{
+		{NodeName: "node-a", Username: "john", Active: true},
+		{NodeName: "node-b", Username: "john", Active: true},
+		{NodeName: "node-a", Username: "jane", Active: true},
+	} {
+		srv.AddAccount(a)
+	}
+	at := time.Unix(1000, 0)
+	srv.AddTraffic("node-a", "john", at, 100, 500)
+	srv.AddTraffic("node-b", "john", at, 0, 300)
+	srv.AddTraffic("node-a", "jane", at, 0, 250)
+	return srv
+}
+
+func TestCheck(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+	at := time.Unix(1000, 0)
+
+	warned := 0
+	engine := New(srv.Client(), &Policy{
+		AccountLimits:       map[string]foxyproxy.ByteSize{"john": 800},
+		DefaultAccountLimit: 300,
+		Enforce:             true,
+		DryRun:              true,
+		OnWarning:           func(*Warning) { warned++ },
+	})
+	result, err := engine.Check(context.Background(), at, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"node node-a: 85% of node quota used (850 B of 1 KB)",
+		"account jane: 83% of account limit used (250 B of 300 B)",
+		"account john: 112% of account limit used (900 B of 800 B)",
+	}
+	if fmt.Sprint(result.Warnings) != fmt.Sprint(expected) || warned != 3 {
+		t.Errorf("expected warnings: %v, got %v (%d)", expected, result.Warnings, warned)
+	}
+	if len(result.Deactivations) != 1 {
+		t.Fatalf("expected deactivations: %d, got %d", 1, len(result.Deactivations))
+	}
+	d := result.Deactivations[0]
+	if !d.DryRun || d.Count != 0 || fmt.Sprint(d.NodeNames) != "[node-a node-b]" || d.Err != nil {
+		t.Errorf("unexpected dry-run deactivation: %+v", d)
+	}
+	for _, a := range srv.Accounts() {
+		if !a.Active {
+			t.Errorf("expected account %s on %s to be active in dry-run mode", a.Username, a.NodeName)
+		}
+	}
+}
+
+func TestCheckEnforce(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+	at := time.Unix(1000, 0)
+
+	engine := New(srv.Client(), &Policy{
+		Thresholds:    []float64{1},
+		AccountLimits: map[string]foxyproxy.ByteSize{"john": 800},
+		Enforce:       true,
+		Comment:       func(w *Warning) string { return "over limit: " + w.Username },
+	})
+	result, err := engine.Check(context.Background(), at, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Warnings) != 1 || len(result.Deactivations) != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if d := result.Deactivations[0]; d.Count != 2 || d.Err != nil {
+		t.Errorf("unexpected deactivation: %+v", d)
+	}
+	for _, a := range srv.Accounts() {
+		if a.Username == "john" && (a.Active || a.Comment != "over limit: john") {
+			t.Errorf("expected john on %s to be deactivated with a comment, got %+v", a.NodeName, a)
+		}
+		if a.Username == "jane" && !a.Active {
+			t.Error("expected jane to be active")
+		}
+	}
+}
+
+func TestCheckEnforceParallelism(t *testing.T) {
+	srv := foxyproxytest.NewServer()
+	defer srv.Close()
+	srv.AddNode(&foxyproxy.Node{Name: "node-a"})
+	at := time.Unix(1000, 0)
+	for i := 0; i < 20; i++ {
+		username := fmt.Sprintf("user-%02d", i)
+		srv.AddAccount(&foxyproxytest.AccountState{NodeName: "node-a", Username: username, Active: true})
+		srv.AddTraffic("node-a", username, at, 0, 100)
+	}
+	var (
+		mu                sync.Mutex
+		running, maxCalls int
+	)
+	c := srv.Client()
+	c.Use(func(next foxyproxy.Doer) foxyproxy.Doer {
+		return foxyproxy.DoerFunc(func(ctx context.Context, req *foxyproxy.Request) (*foxyproxy.Response, error) {
+			if req.Method != http.MethodPatch {
+				return next.Do(ctx, req)
+			}
+			mu.Lock()
+			running++
+			if running > maxCalls {
+				maxCalls = running
+			}
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			defer func() {
+				mu.Lock()
+				running--
+				mu.Unlock()
+			}()
+			return next.Do(ctx, req)
+		})
+	})
+
+	engine := New(c, &Policy{DefaultAccountLimit: 50, Enforce: true, Parallelism: 3})
+	result, err := engine.Check(context.Background(), at, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Deactivations) != 20 {
+		t.Fatalf("expected deactivations: %d, got %d", 20, len(result.Deactivations))
+	}
+	for _, d := range result.Deactivations {
+		if d.Count != 1 || d.Err != nil {
+			t.Errorf("unexpected deactivation: %+v", d)
+		}
+	}
+	if maxCalls > 3 {
+		t.Errorf("expected at most %d concurrent deactivations, got %d", 3, maxCalls)
+	}
+}
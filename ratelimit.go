@@ -0,0 +1,92 @@
+package foxyproxy
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimit configures a token bucket rate limiter.
+type RateLimit struct {
+	// RequestsPerSecond is the rate at which tokens are added to the bucket. Zero or negative
+	// values disable the limiter.
+	RequestsPerSecond float64
+	// Burst is the size of the bucket, i.e. the maximum number of requests sent at once. Defaults
+	// to 1.
+	Burst int
+}
+
+// rateLimiter is a token bucket safe for concurrent use. Tokens are reserved up front, so waiting
+// callers are served in the order they arrived.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rl *RateLimit) *rateLimiter {
+	if rl == nil || rl.RequestsPerSecond <= 0 {
+		return nil
+	}
+	burst := float64(rl.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:   rl.RequestsPerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a token is available or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	err := ctx.Err()
+	if deadline, ok := ctx.Deadline(); ok && err == nil && deadline.Before(now.Add(delay)) {
+		err = context.DeadlineExceeded
+	}
+	if err == nil {
+		err = sleep(ctx, delay)
+	}
+	if err != nil {
+		// give back the reserved token
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+	}
+	return err
+}
+
+// limiter returns the rate limiter which applies to requests with the specified method.
+func (c *Client) limiter(method string) *rateLimiter {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return c.readLimiter
+	default:
+		return c.mutatingLimiter
+	}
+}
@@ -0,0 +1,52 @@
+package foxyproxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"count":1}`))
+	}))
+	defer ts.Close()
+	c := newTestClient(t, &NewClientParams{
+		EndpointBaseURL: ts.URL,
+		RateLimit:       &RateLimit{RequestsPerSecond: 20, Burst: 1},
+	})
+
+	// the first request uses the burst, the next two wait 50ms each
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := c.CountAccounts(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected requests to take at least %s, took %s", 90*time.Millisecond, elapsed)
+	}
+
+	// a deadline shorter than the wait fails without sending the request
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if _, err := c.CountAccountsContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error: %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestMutatingRateLimit(t *testing.T) {
+	c := newTestClient(t, &NewClientParams{
+		RateLimit:         &RateLimit{RequestsPerSecond: 10},
+		MutatingRateLimit: &RateLimit{RequestsPerSecond: 1},
+	})
+	if c.limiter(http.MethodGet) != c.readLimiter {
+		t.Error("expected GET requests to use the read limiter")
+	}
+	if c.limiter(http.MethodPatch) != c.mutatingLimiter || c.mutatingLimiter == c.readLimiter {
+		t.Error("expected PATCH requests to use the mutating limiter")
+	}
+}
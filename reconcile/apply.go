@@ -0,0 +1,165 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"github.com/jsignanini/foxyproxy-reseller-go/internal/parallel"
+)
+
+// ErrSkipped is matched by the errors of operations skipped after an earlier operation of the same
+// username failed.
+var ErrSkipped = errors.New("reconcile: skipped")
+
+// OperationResult is the result of an operation.
+type OperationResult struct {
+	Operation *Operation
+	// Count is the number of accounts affected, as returned by the api. It is zero in dry-run
+	// mode.
+	Count  int
+	DryRun bool
+	Err    error
+}
+
+// Result is the result of applying a plan.
+type Result struct {
+	// Operations holds the result of each operation, in the order of the plan.
+	Operations []*OperationResult
+	// PasswordRefs are the password references of the plan applied, by username, i.e. those of the
+	// usernames whose operations all succeeded. Merge them into Params.AppliedPasswordRefs for the
+	// next reconciliation.
+	PasswordRefs map[string]string
+}
+
+// Err returns the errors of the failed operations joined, or nil if all succeeded.
+func (r *Result) Err() error {
+	errs := []error{}
+	for _, res := range r.Operations {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.Operation, res.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Apply performs the operations of plan. Copies are performed first, then the operations of up to
+// Params.Parallelism usernames run concurrently; the operations of a username run in order and stop
+// at the first failure. The operations of a username are skipped if a copy of its accounts failed.
+// The returned error is only set if ctx is done, in which case the operations which didn't run
+// hold its error.
+func (r *Reconciler) Apply(ctx context.Context, plan *Plan) (*Result, error) {
+	result := &Result{
+		Operations:   make([]*OperationResult, len(plan.Operations)),
+		PasswordRefs: map[string]string{},
+	}
+	groups := map[string][]int{}
+	order := []string{}
+	failedCopies := map[string]*Operation{}
+	for i, op := range plan.Operations {
+		res := &OperationResult{Operation: op, DryRun: r.params.DryRun}
+		result.Operations[i] = res
+		if op.Kind == Copy {
+			r.apply(ctx, res)
+			if res.Err != nil {
+				for _, u := range op.Usernames {
+					failedCopies[u] = op
+				}
+			}
+			continue
+		}
+		if _, ok := groups[op.Username]; !ok {
+			order = append(order, op.Username)
+		}
+		groups[op.Username] = append(groups[op.Username], i)
+	}
+
+	var mu sync.Mutex
+	started := make([]bool, len(order))
+	err := parallel.ForEach(ctx, parallelism(r.params.Parallelism), order, func(g int, username string) {
+		started[g] = true
+		failed := failedCopies[username]
+		for _, i := range groups[username] {
+			res := result.Operations[i]
+			if failed != nil {
+				res.Err = fmt.Errorf("%w: %s failed", ErrSkipped, failed.Kind)
+				continue
+			}
+			r.apply(ctx, res)
+			if res.Err != nil {
+				failed = res.Operation
+			}
+		}
+		if ref, ok := plan.PasswordRefs[username]; ok && failed == nil && !r.params.DryRun {
+			mu.Lock()
+			result.PasswordRefs[username] = ref
+			mu.Unlock()
+		}
+	})
+	if err != nil {
+		for g, username := range order {
+			if !started[g] {
+				for _, i := range groups[username] {
+					result.Operations[i].Err = err
+				}
+			}
+		}
+	}
+	return result, err
+}
+
+// apply performs a single operation, unless in dry-run mode.
+func (r *Reconciler) apply(ctx context.Context, res *OperationResult) {
+	if res.DryRun {
+		return
+	}
+	if err := ctx.Err(); err != nil {
+		res.Err = err
+		return
+	}
+	op := res.Operation
+	common := &foxyproxy.CommonProperties{Comment: r.params.Comment, NodeNames: op.NodeNames}
+	switch op.Kind {
+	case Copy:
+		res.Count, res.Err = r.api.CopyAccountsContext(ctx, op.FromNode, op.NodeNames)
+	case Create:
+		password, err := r.password(ctx, op.PasswordRef)
+		if err != nil {
+			res.Err = err
+			return
+		}
+		accounts, err := r.api.CreateAccountsContext(ctx, op.Username, password, common)
+		res.Count, res.Err = len(accounts), err
+	case UpdatePassword:
+		password, err := r.password(ctx, op.PasswordRef)
+		if err != nil {
+			res.Err = err
+			return
+		}
+		res.Count, res.Err = r.api.UpdatePasswordWithParamsContext(ctx, op.Username, password, common)
+	case Activate:
+		res.Count, res.Err = r.api.ActivateAccountWithParamsContext(ctx, op.Username, common)
+	case Deactivate:
+		res.Count, res.Err = r.api.DeactivateAccountWithParamsContext(ctx, op.Username, common)
+	case Delete:
+		res.Count, res.Err = r.api.DeleteAccountsWithParamsContext(ctx, op.Username, &foxyproxy.DeleteAccountsParams{
+			IncludeHistory:   r.params.DeleteHistory,
+			CommonProperties: foxyproxy.CommonProperties{NodeNames: op.NodeNames},
+		})
+	default:
+		res.Err = fmt.Errorf("reconcile: unknown operation kind %s", op.Kind)
+	}
+}
+
+func (r *Reconciler) password(ctx context.Context, ref string) (string, error) {
+	if r.params.Passwords == nil {
+		return "", errors.New("reconcile: no password resolver (Params.Passwords)")
+	}
+	password, err := r.params.Passwords(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("reconcile: resolving password %q: %w", ref, err)
+	}
+	return password, nil
+}
@@ -0,0 +1,495 @@
+// Package reconcile makes the accounts of a reseller pool match a desired state. A Reconciler reads
+// the actual state of the desired accounts, plans the operations which bring it to the desired
+// state and applies them:
+//
+//	r := reconcile.New(client, &reconcile.Params{
+//		Passwords: func(ctx context.Context, ref string) (string, error) {
+//			return secrets.Get(ctx, ref)
+//		},
+//	})
+//	plan, err := r.Plan(ctx, []*reconcile.Account{
+//		{Username: "john", PasswordRef: "john-v2", Active: true, NodeNames: []string{"node-1", "node-2"}},
+//		{Username: "jane", PasswordRef: "jane-v1", Active: false},
+//	})
+//	fmt.Print(plan)
+//	result, err := r.Apply(ctx, plan)
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"github.com/jsignanini/foxyproxy-reseller-go/internal/parallel"
+)
+
+// minCopyAccounts is the minimum number of accounts a copy must create to replace their creation.
+const minCopyAccounts = 2
+
+// Account is the desired state of the accounts with a username.
+type Account struct {
+	Username string
+	// PasswordRef references the password of the account, e.g. a hash or a secret version, and is
+	// resolved by Params.Passwords when the password must be sent. The api never returns
+	// passwords, so a password is only updated when PasswordRef differs from the reference last
+	// applied (see Params.AppliedPasswordRefs), or when the account is created on more nodes and
+	// no reference was applied. Empty means the password is not managed: it is never updated, and
+	// accounts can't be created.
+	PasswordRef string
+	Active      bool
+	// NodeNames are the nodes the account must exist on. Empty means all nodes.
+	NodeNames []string
+}
+
+// Params configures a reconciler.
+type Params struct {
+	// Passwords resolves a password reference to the password. It is called when creating
+	// accounts and updating passwords, never in dry-run mode.
+	Passwords func(ctx context.Context, ref string) (string, error)
+	// AppliedPasswordRefs are the password references last applied, by username, e.g. persisted
+	// from Result.PasswordRefs. Passwords of accounts missing from it are only updated when the
+	// account is created on more nodes, so that all its nodes share the same password.
+	AppliedPasswordRefs map[string]string
+	// Prune deletes the accounts whose username is not in the desired state. All accounts of the
+	// pool are read to find them.
+	Prune bool
+	// DeleteHistory deletes the history of deleted accounts.
+	DeleteHistory bool
+	// Comment is recorded on created, activated, deactivated and updated accounts.
+	Comment string
+	// DryRun reports the operations Apply would perform without performing them.
+	DryRun bool
+	// Parallelism is the number of usernames read and reconciled concurrently. Defaults to
+	// foxyproxy.DefaultParallelism.
+	Parallelism int
+}
+
+// Kind is the kind of an operation.
+type Kind int
+
+// Kinds of operations, in the order they are applied to a username.
+const (
+	// Copy copies all accounts of a node to other nodes.
+	Copy Kind = iota + 1
+	Create
+	UpdatePassword
+	Activate
+	Deactivate
+	Delete
+)
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case Copy:
+		return "copy"
+	case Create:
+		return "create"
+	case UpdatePassword:
+		return "password"
+	case Activate:
+		return "activate"
+	case Deactivate:
+		return "deactivate"
+	case Delete:
+		return "delete"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Operation is a change to the accounts of a username, or a copy of the accounts of a node.
+type Operation struct {
+	Kind Kind
+	// Username is empty for copies.
+	Username string
+	// FromNode is the source node of copies.
+	FromNode string
+	// NodeNames are the nodes the operation applies to, or the target nodes of copies.
+	NodeNames []string
+	// Usernames are the usernames of the accounts copied, for copies.
+	Usernames []string
+	// PasswordRef is the password reference of creations and password updates.
+	PasswordRef string
+}
+
+// String returns a line of a plan's diff, e.g. "+ create john on node-1, node-2".
+func (o *Operation) String() string {
+	nodes := strings.Join(o.NodeNames, ", ")
+	switch o.Kind {
+	case Copy:
+		return fmt.Sprintf("> copy all accounts of %s to %s", o.FromNode, nodes)
+	case Create:
+		return fmt.Sprintf("+ create %s on %s", o.Username, nodes)
+	case Delete:
+		return fmt.Sprintf("- delete %s on %s", o.Username, nodes)
+	case UpdatePassword:
+		return fmt.Sprintf("~ update the password of %s on %s", o.Username, nodes)
+	default:
+		return fmt.Sprintf("~ %s %s on %s", o.Kind, o.Username, nodes)
+	}
+}
+
+// Plan is the list of operations bringing the actual state to the desired state. Copies come
+// first, then the operations of each username in the order of their kind.
+type Plan struct {
+	Operations []*Operation
+	// PasswordRefs are the password references all the accounts of a username have once the plan
+	// is applied, for new usernames and password updates.
+	PasswordRefs map[string]string
+}
+
+// Empty reports whether the actual state already matches the desired state.
+func (p *Plan) Empty() bool {
+	return len(p.Operations) == 0
+}
+
+// String returns a human-readable diff of the plan, one operation per line.
+func (p *Plan) String() string {
+	if p.Empty() {
+		return "no changes\n"
+	}
+	var b strings.Builder
+	for _, op := range p.Operations {
+		b.WriteString(op.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Reconciler plans and applies the changes making the accounts of a reseller pool match a desired
+// state.
+type Reconciler struct {
+	api    foxyproxy.API
+	params *Params
+}
+
+// New generates a new reconciler reading and changing the accounts through api. params may be
+// nil.
+func New(api foxyproxy.API, params *Params) *Reconciler {
+	if params == nil {
+		params = &Params{}
+	}
+	return &Reconciler{
+		api:    api,
+		params: params,
+	}
+}
+
+// state is the actual state of accounts, by username and node name.
+type state map[string]map[string]*foxyproxy.Account
+
+// Plan reads the actual state of the desired accounts, and of all accounts if Prune is set, and
+// returns the operations bringing it to desired.
+func (r *Reconciler) Plan(ctx context.Context, desired []*Account) (*Plan, error) {
+	want := map[string]*Account{}
+	usernames := []string{}
+	allNodes := false
+	for _, d := range desired {
+		if d.Username == "" {
+			return nil, errors.New("reconcile: empty username")
+		}
+		if _, ok := want[d.Username]; ok {
+			return nil, fmt.Errorf("reconcile: duplicate username %q", d.Username)
+		}
+		want[d.Username] = d
+		usernames = append(usernames, d.Username)
+		allNodes = allNodes || len(d.NodeNames) == 0
+	}
+	sort.Strings(usernames)
+
+	var nodeNames []string
+	if allNodes {
+		it := r.api.Nodes(ctx, nil)
+		for it.Next() {
+			nodeNames = append(nodeNames, it.Node().Name)
+		}
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+	}
+	wantNodes := map[string][]string{}
+	for u, d := range want {
+		nodes := d.NodeNames
+		if len(nodes) == 0 {
+			nodes = nodeNames
+		}
+		wantNodes[u] = sortedUnique(nodes)
+	}
+
+	actual, full, err := r.actual(ctx, usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{PasswordRefs: map[string]string{}}
+	copied, err := r.planCopies(ctx, plan, want, wantNodes, actual, full)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range usernames {
+		d, have := want[u], actual[u]
+		var create, keep, copiedTo, activate, deactivate, del []string
+		for _, n := range wantNodes[u] {
+			a, ok := have[n]
+			switch {
+			case copied[n][u]:
+				copiedTo = append(copiedTo, n)
+			case !ok:
+				create = append(create, n)
+			default:
+				keep = append(keep, n)
+				if a.Active != d.Active {
+					if d.Active {
+						activate = append(activate, n)
+					} else {
+						deactivate = append(deactivate, n)
+					}
+				}
+			}
+		}
+		for n := range have {
+			if !contains(wantNodes[u], n) {
+				del = append(del, n)
+			}
+		}
+		sort.Strings(del)
+
+		applied, known := r.params.AppliedPasswordRefs[u]
+		updatePassword := known && d.PasswordRef != "" && applied != d.PasswordRef
+		if len(create) > 0 {
+			if d.PasswordRef == "" {
+				return nil, fmt.Errorf("reconcile: account %s: missing password reference to create it", u)
+			}
+			plan.add(&Operation{Kind: Create, Username: u, NodeNames: create, PasswordRef: d.PasswordRef})
+			if len(have) == 0 {
+				plan.PasswordRefs[u] = d.PasswordRef
+			} else if !known {
+				// the password of the existing accounts is unknown: set it on all of them so it
+				// matches the created ones
+				updatePassword = true
+			}
+			if !d.Active {
+				// created accounts are active
+				deactivate = sortedUnique(append(deactivate, create...))
+			}
+		}
+		if updatePassword {
+			if nodes := sortedUnique(append(keep, copiedTo...)); len(nodes) > 0 {
+				plan.add(&Operation{Kind: UpdatePassword, Username: u, NodeNames: nodes, PasswordRef: d.PasswordRef})
+				plan.PasswordRefs[u] = d.PasswordRef
+			}
+		}
+		plan.add(&Operation{Kind: Activate, Username: u, NodeNames: activate})
+		plan.add(&Operation{Kind: Deactivate, Username: u, NodeNames: deactivate})
+		plan.add(&Operation{Kind: Delete, Username: u, NodeNames: del})
+	}
+
+	if r.params.Prune {
+		undesired := []string{}
+		for u := range actual {
+			if _, ok := want[u]; !ok {
+				undesired = append(undesired, u)
+			}
+		}
+		sort.Strings(undesired)
+		for _, u := range undesired {
+			nodes := []string{}
+			for n := range actual[u] {
+				nodes = append(nodes, n)
+			}
+			sort.Strings(nodes)
+			plan.add(&Operation{Kind: Delete, Username: u, NodeNames: nodes})
+		}
+	}
+	return plan, nil
+}
+
+// add appends op to the plan, unless it applies to no node.
+func (p *Plan) add(op *Operation) {
+	if len(op.NodeNames) > 0 {
+		p.Operations = append(p.Operations, op)
+	}
+}
+
+// actual reads the accounts of usernames, or all accounts if Prune is set, in which case full is
+// true.
+func (r *Reconciler) actual(ctx context.Context, usernames []string) (s state, full bool, err error) {
+	s = state{}
+	if r.params.Prune {
+		it := r.api.Accounts(ctx, nil)
+		for it.Next() {
+			s.add(it.Account())
+		}
+		return s, true, it.Err()
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	err = parallel.ForEach(ctx, parallelism(r.params.Parallelism), usernames, func(_ int, u string) {
+		accounts, err := collect(r.api.AccountsByUsername(ctx, u, nil))
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil && !errors.Is(err, foxyproxy.ErrNotFound) {
+			errs = append(errs, err)
+			return
+		}
+		for _, a := range accounts {
+			s.add(a)
+		}
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return s, false, errors.Join(errs...)
+}
+
+func (s state) add(a *foxyproxy.Account) {
+	if a.Node == nil {
+		return
+	}
+	if s[a.Username] == nil {
+		s[a.Username] = map[string]*foxyproxy.Account{}
+	}
+	s[a.Username][a.Node.Name] = a
+}
+
+// planCopies adds to plan the copies of nodes whose accounts are all missing, with the same
+// active flag, on another node, and returns the usernames they create by target node.
+func (r *Reconciler) planCopies(ctx context.Context, plan *Plan, want map[string]*Account, wantNodes map[string][]string, actual state, full bool) (map[string]map[string]bool, error) {
+	// the desired accounts missing from each node
+	missing := map[string]map[string]bool{}
+	for u, nodes := range wantNodes {
+		for _, n := range nodes {
+			if _, ok := actual[u][n]; !ok {
+				if missing[n] == nil {
+					missing[n] = map[string]bool{}
+				}
+				missing[n][u] = true
+			}
+		}
+	}
+	targets := []string{}
+	for n, usernames := range missing {
+		if len(usernames) >= minCopyAccounts {
+			targets = append(targets, n)
+		}
+	}
+	sort.Strings(targets)
+
+	nodeAccounts := map[string][]*foxyproxy.Account{}
+	accountsOf := func(n string) ([]*foxyproxy.Account, error) {
+		if accounts, ok := nodeAccounts[n]; ok {
+			return accounts, nil
+		}
+		var accounts []*foxyproxy.Account
+		if full {
+			for _, byNode := range actual {
+				if a, ok := byNode[n]; ok {
+					accounts = append(accounts, a)
+				}
+			}
+		} else {
+			var err error
+			if accounts, err = collect(r.api.AccountsByNode(ctx, n, nil)); err != nil {
+				return nil, err
+			}
+		}
+		nodeAccounts[n] = accounts
+		return accounts, nil
+	}
+
+	copied := map[string]map[string]bool{}
+	copies := map[string][]string{}
+	copiedFrom := map[string][]string{}
+	for _, target := range targets {
+		// candidate sources hold at least one of the missing accounts
+		sources := map[string]bool{}
+		for u := range missing[target] {
+			for n := range actual[u] {
+				sources[n] = true
+			}
+		}
+		for _, source := range sortedKeys(sources) {
+			accounts, err := accountsOf(source)
+			if err != nil {
+				return nil, err
+			}
+			if len(accounts) < minCopyAccounts || !copyable(accounts, missing[target], want) {
+				continue
+			}
+			copied[target] = map[string]bool{}
+			usernames := []string{}
+			for _, a := range accounts {
+				copied[target][a.Username] = true
+				usernames = append(usernames, a.Username)
+			}
+			copies[source] = append(copies[source], target)
+			copiedFrom[source] = sortedUnique(usernames)
+			break
+		}
+	}
+	for _, source := range sortedKeys(copies) {
+		plan.add(&Operation{Kind: Copy, FromNode: source, NodeNames: copies[source], Usernames: copiedFrom[source]})
+	}
+	return copied, nil
+}
+
+// copyable reports whether copying accounts creates missing accounts only, with their desired
+// active flag.
+func copyable(accounts []*foxyproxy.Account, missing map[string]bool, want map[string]*Account) bool {
+	for _, a := range accounts {
+		if !missing[a.Username] || want[a.Username].Active != a.Active {
+			return false
+		}
+	}
+	return true
+}
+
+func collect(it *foxyproxy.AccountIterator) ([]*foxyproxy.Account, error) {
+	accounts := []*foxyproxy.Account{}
+	for it.Next() {
+		accounts = append(accounts, it.Account())
+	}
+	return accounts, it.Err()
+}
+
+func parallelism(p int) int {
+	if p < 1 {
+		return foxyproxy.DefaultParallelism
+	}
+	return p
+}
+
+func sortedUnique(values []string) []string {
+	set := map[string]bool{}
+	for _, v := range values {
+		set[v] = true
+	}
+	return sortedKeys(set)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,319 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"github.com/jsignanini/foxyproxy-reseller-go/foxyproxytest"
+)
+
+var passwords = map[string]string{"john-v1": "john-secret-1", "john-v2": "john-secret-2", "jane-v1": "jane-secret", "ann-v1": "ann-secret"}
+
+func resolve(ctx context.Context, ref string) (string, error) {
+	password, ok := passwords[ref]
+	if !ok {
+		return "", errors.New("unknown password reference")
+	}
+	return password, nil
+}
+
+func newServer() *foxyproxytest.Server {
+	srv := foxyproxytest.NewServer()
+	for _, name := range []string{"node-1", "node-2", "node-3"} {
+		srv.AddNode(&foxyproxy.Node{Name: name, Active: true})
+	}
+	srv.AddAccount(&foxyproxytest.AccountState{NodeName: "node-1", Username: "john", Password: "john-secret-1", Active: true})
+	srv.AddAccount(&foxyproxytest.AccountState{NodeName: "node-2", Username: "john", Password: "john-secret-1", Active: false})
+	srv.AddAccount(&foxyproxytest.AccountState{NodeName: "node-1", Username: "jane", Password: "jane-secret", Active: true})
+	srv.AddAccount(&foxyproxytest.AccountState{NodeName: "node-2", Username: "max", Password: "max-secret", Active: true})
+	return srv
+}
+
+var desired = []*Account{
+	{Username: "john", PasswordRef: "john-v2", Active: true, NodeNames: []string{"node-1", "node-3"}},
+	{Username: "jane", PasswordRef: "jane-v1", Active: false, NodeNames: []string{"node-1", "node-2"}},
+	{Username: "ann", PasswordRef: "ann-v1", Active: true},
+}
+
+// accounts returns the accounts of srv as "username@node:password:active" strings.
+func accounts(srv *foxyproxytest.Server) []string {
+	states := []string{}
+	for _, a := range srv.Accounts() {
+		active := "inactive"
+		if a.Active {
+			active = "active"
+		}
+		states = append(states, a.Username+"@"+a.NodeName+":"+a.Password+":"+active)
+	}
+	sort.Strings(states)
+	return states
+}
+
+func TestReconcile(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+	ctx := context.Background()
+	applied := map[string]string{"john": "john-v1"}
+	r := New(srv.Client(), &Params{Passwords: resolve, AppliedPasswordRefs: applied, Parallelism: 2})
+
+	plan, err := r.Plan(ctx, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `+ create ann on node-1, node-2, node-3
++ create jane on node-2
+~ update the password of jane on node-1
+~ deactivate jane on node-1, node-2
++ create john on node-3
+~ update the password of john on node-1
+- delete john on node-2
+`
+	if plan.String() != expected {
+		t.Errorf("expected plan:\n%s\ngot:\n%s", expected, plan)
+	}
+
+	result, err := r.Apply(ctx, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := result.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if c := result.Operations[0].Count; c != 3 {
+		t.Errorf("expected %d accounts created, got %d", 3, c)
+	}
+	expectedAccounts := []string{
+		"ann@node-1:ann-secret:active",
+		"ann@node-2:ann-secret:active",
+		"ann@node-3:ann-secret:active",
+		"jane@node-1:jane-secret:inactive",
+		"jane@node-2:jane-secret:inactive",
+		"john@node-1:john-secret-2:active",
+		"john@node-3:john-secret-2:active",
+		"max@node-2:max-secret:active",
+	}
+	if got := accounts(srv); strings.Join(got, "\n") != strings.Join(expectedAccounts, "\n") {
+		t.Errorf("expected accounts:\n%s\ngot:\n%s", strings.Join(expectedAccounts, "\n"), strings.Join(got, "\n"))
+	}
+	if result.PasswordRefs["john"] != "john-v2" || result.PasswordRefs["ann"] != "ann-v1" || result.PasswordRefs["jane"] != "jane-v1" || len(result.PasswordRefs) != 3 {
+		t.Errorf("unexpected applied password references: %v", result.PasswordRefs)
+	}
+
+	for username, ref := range result.PasswordRefs {
+		applied[username] = ref
+	}
+	if plan, err = r.Plan(ctx, desired); err != nil {
+		t.Fatal(err)
+	}
+	if !plan.Empty() {
+		t.Errorf("expected an empty plan, got:\n%s", plan)
+	}
+}
+
+func TestReconcilePruneDryRun(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+	ctx := context.Background()
+	r := New(srv.Client(), &Params{Prune: true, DryRun: true})
+
+	plan, err := r.Plan(ctx, desired[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `+ create john on node-3
+~ update the password of john on node-1
+- delete john on node-2
+- delete jane on node-1
+- delete max on node-2
+`
+	if plan.String() != expected {
+		t.Errorf("expected plan:\n%s\ngot:\n%s", expected, plan)
+	}
+	before := accounts(srv)
+	result, err := r.Apply(ctx, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, res := range result.Operations {
+		if !res.DryRun || res.Err != nil || res.Count != 0 {
+			t.Errorf("unexpected dry-run result: %+v", res)
+		}
+	}
+	if after := accounts(srv); strings.Join(after, ",") != strings.Join(before, ",") {
+		t.Errorf("accounts changed in dry-run mode: %v", after)
+	}
+}
+
+func TestReconcileCopy(t *testing.T) {
+	srv := foxyproxytest.NewServer()
+	defer srv.Close()
+	srv.AddNode(&foxyproxy.Node{Name: "node-1"})
+	srv.AddNode(&foxyproxy.Node{Name: "node-2"})
+	srv.AddAccount(&foxyproxytest.AccountState{NodeName: "node-1", Username: "john", Password: "john-secret-1", Active: true})
+	srv.AddAccount(&foxyproxytest.AccountState{NodeName: "node-1", Username: "jane", Password: "jane-secret", Active: false})
+	ctx := context.Background()
+	r := New(srv.Client(), nil)
+
+	plan, err := r.Plan(ctx, []*Account{
+		{Username: "john", Active: true},
+		{Username: "jane", Active: false},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "> copy all accounts of node-1 to node-2\n"; plan.String() != expected {
+		t.Errorf("expected plan:\n%s\ngot:\n%s", expected, plan)
+	}
+	result, err := r.Apply(ctx, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Err() != nil || result.Operations[0].Count != 2 {
+		t.Errorf("unexpected copy result: %+v", result.Operations[0])
+	}
+	if got := accounts(srv); len(got) != 4 || got[1] != "jane@node-2:jane-secret:inactive" || got[3] != "john@node-2:john-secret-1:active" {
+		t.Errorf("unexpected accounts: %v", got)
+	}
+}
+
+func TestReconcileFailure(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+	ctx := context.Background()
+	r := New(srv.Client(), &Params{Passwords: resolve, AppliedPasswordRefs: map[string]string{"john": "john-v1"}})
+	plan, err := r.Plan(ctx, desired[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv.InjectFault(foxyproxytest.Fault{PathPrefix: "/accounts/update-password/john", Status: http.StatusInternalServerError})
+	result, err := r.Apply(ctx, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Operations) != 3 {
+		t.Fatalf("expected %d operations, got %d", 3, len(result.Operations))
+	}
+	if res := result.Operations[0]; res.Err != nil || res.Count != 1 {
+		t.Errorf("unexpected create result: %+v", res)
+	}
+	if res := result.Operations[1]; !errors.Is(res.Err, foxyproxy.ErrServer) {
+		t.Errorf("expected error: %v, got %v", foxyproxy.ErrServer, res.Err)
+	}
+	if res := result.Operations[2]; !errors.Is(res.Err, ErrSkipped) {
+		t.Errorf("expected error: %v, got %v", ErrSkipped, res.Err)
+	}
+	if !errors.Is(result.Err(), foxyproxy.ErrServer) || len(result.PasswordRefs) != 0 {
+		t.Errorf("unexpected result: %v, %v", result.Err(), result.PasswordRefs)
+	}
+}
+
+func TestPlanErrors(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+	r := New(srv.Client(), nil)
+	if _, err := r.Plan(context.Background(), []*Account{{Username: "john"}, {Username: "john"}}); err == nil {
+		t.Error("expected an error for the duplicate username")
+	}
+	if _, err := r.Plan(context.Background(), []*Account{{Username: "new", NodeNames: []string{"node-1"}}}); err == nil || !strings.Contains(err.Error(), "missing password reference") {
+		t.Errorf("expected a missing password reference error, got %v", err)
+	}
+}
+
+func TestReconcileUnmanagedPassword(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+	ctx := context.Background()
+	r := New(srv.Client(), &Params{Passwords: resolve, AppliedPasswordRefs: map[string]string{"john": "john-v1"}})
+	plan, err := r.Plan(ctx, []*Account{{Username: "john", Active: false, NodeNames: []string{"node-1", "node-2"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "~ deactivate john on node-1\n"; plan.String() != expected {
+		t.Errorf("expected plan:\n%s\ngot:\n%s", expected, plan)
+	}
+	result, err := r.Apply(ctx, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := result.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.PasswordRefs) != 0 {
+		t.Errorf("unexpected applied password references: %v", result.PasswordRefs)
+	}
+	for _, a := range srv.Accounts() {
+		if a.Username == "john" && (a.Active || a.Password != "john-secret-1") {
+			t.Errorf("unexpected state of john on %s: %+v", a.NodeName, a)
+		}
+	}
+}
+
+func TestReconcileCanceled(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+	r := New(srv.Client(), &Params{Passwords: resolve})
+	plan, err := r.Plan(context.Background(), desired[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := r.Apply(ctx, plan)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error: %v, got %v", context.Canceled, err)
+	}
+	for _, res := range result.Operations {
+		if !errors.Is(res.Err, context.Canceled) {
+			t.Errorf("%s: expected error: %v, got %v", res.Operation, context.Canceled, res.Err)
+		}
+	}
+	if !errors.Is(result.Err(), context.Canceled) || len(result.PasswordRefs) != 0 {
+		t.Errorf("unexpected result: %v, %v", result.Err(), result.PasswordRefs)
+	}
+}
+
+func TestReconcileCopyFailure(t *testing.T) {
+	srv := foxyproxytest.NewServer()
+	defer srv.Close()
+	srv.AddNode(&foxyproxy.Node{Name: "node-1"})
+	srv.AddNode(&foxyproxy.Node{Name: "node-2"})
+	srv.AddAccount(&foxyproxytest.AccountState{NodeName: "node-1", Username: "john", Password: "john-secret-1", Active: true})
+	srv.AddAccount(&foxyproxytest.AccountState{NodeName: "node-1", Username: "jane", Password: "jane-secret", Active: true})
+	ctx := context.Background()
+	r := New(srv.Client(), &Params{Passwords: resolve, AppliedPasswordRefs: map[string]string{"john": "john-v1"}})
+	plan, err := r.Plan(ctx, []*Account{
+		{Username: "john", PasswordRef: "john-v2", Active: true},
+		{Username: "jane", PasswordRef: "jane-v1", Active: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `> copy all accounts of node-1 to node-2
+~ update the password of john on node-1, node-2
+`
+	if plan.String() != expected {
+		t.Fatalf("expected plan:\n%s\ngot:\n%s", expected, plan)
+	}
+
+	srv.InjectFault(foxyproxytest.Fault{PathPrefix: "/accounts/copy-all/", Status: http.StatusInternalServerError})
+	result, err := r.Apply(ctx, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := result.Operations[0]; !errors.Is(res.Err, foxyproxy.ErrServer) {
+		t.Errorf("expected error: %v, got %v", foxyproxy.ErrServer, res.Err)
+	}
+	if res := result.Operations[1]; !errors.Is(res.Err, ErrSkipped) {
+		t.Errorf("expected error: %v, got %v", ErrSkipped, res.Err)
+	}
+	if len(result.PasswordRefs) != 0 {
+		t.Errorf("expected no applied password references, got %v", result.PasswordRefs)
+	}
+}
@@ -0,0 +1,209 @@
+package foxyproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/jsignanini/foxyproxy-reseller-go/internal/parallel"
+)
+
+// ErrUnknownTenant is returned when a tenant is not registered.
+var ErrUnknownTenant = errors.New("foxyproxy: unknown tenant")
+
+// RegistryParams is an object of optional registry parameters.
+type RegistryParams struct {
+	// Middlewares, if set, returns the middlewares installed on the client of each tenant added
+	// with Add, e.g. a metrics collector labeled with the tenant.
+	Middlewares func(tenant string) []Middleware
+}
+
+// Registry holds the clients of several tenants, e.g. reseller brands, and routes calls by tenant
+// key. Each tenant has its own client, so rate limits, retries and middlewares are isolated
+// between tenants. A Registry is safe for concurrent use.
+type Registry struct {
+	params  RegistryParams
+	mu      sync.RWMutex
+	clients map[string]*Client
+}
+
+// NewRegistry generates a new empty registry. params may be nil.
+func NewRegistry(params *RegistryParams) *Registry {
+	r := &Registry{clients: map[string]*Client{}}
+	if params != nil {
+		r.params = *params
+	}
+	return r
+}
+
+// NewRegistryFromConfig generates a new registry with a tenant per profile of config, named after
+// the profile. See Config.Params.
+func NewRegistryFromConfig(config *Config, params *RegistryParams) (*Registry, error) {
+	r := NewRegistry(params)
+	for _, name := range config.Names() {
+		p, err := config.Params(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := r.Add(name, p); err != nil {
+			return nil, fmt.Errorf("%w (profile %q)", err, name)
+		}
+	}
+	return r, nil
+}
+
+// Add generates a new client with params, installs the tenant's middlewares on it and registers
+// it under tenant, replacing any client already registered under it.
+func (r *Registry) Add(tenant string, params *NewClientParams) (*Client, error) {
+	c, err := NewClient(params)
+	if err != nil {
+		return nil, err
+	}
+	if r.params.Middlewares != nil {
+		c.Use(r.params.Middlewares(tenant)...)
+	}
+	r.Register(tenant, c)
+	return c, nil
+}
+
+// Register registers c under tenant, replacing any client already registered under it. The
+// registry's middlewares are not installed on c.
+func (r *Registry) Register(tenant string, c *Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients[tenant] = c
+}
+
+// Remove unregisters tenant.
+func (r *Registry) Remove(tenant string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.clients, tenant)
+}
+
+// Client returns the client of tenant. The returned error matches ErrUnknownTenant if tenant is
+// not registered.
+func (r *Registry) Client(tenant string) (*Client, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.clients[tenant]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownTenant, tenant)
+	}
+	return c, nil
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx carrying the specified tenant key, e.g. in the handler of an
+// incoming request. See Registry.ClientFromContext.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant key carried by ctx, if any.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok && tenant != ""
+}
+
+// ClientFromContext returns the client of the tenant carried by ctx (see WithTenant). The returned
+// error matches ErrUnknownTenant if ctx carries no tenant or it is not registered.
+func (r *Registry) ClientFromContext(ctx context.Context) (*Client, error) {
+	tenant, ok := TenantFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%w: no tenant in context", ErrUnknownTenant)
+	}
+	return r.Client(tenant)
+}
+
+// Tenants returns the sorted keys of the registered tenants.
+func (r *Registry) Tenants() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tenants := make([]string, 0, len(r.clients))
+	for tenant := range r.clients {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// CollectFromTenants calls fn with the client of each tenant of r, running up to parallelism calls
+// concurrently. It returns the results of the successful calls and the errors of the failed ones,
+// both by tenant; a failed call doesn't stop the others. The returned error is only set if ctx is
+// done, in which case the maps hold the calls completed so far.
+func CollectFromTenants[T any](ctx context.Context, r *Registry, parallelism int, fn func(ctx context.Context, tenant string, c *Client) (T, error)) (map[string]T, map[string]error, error) {
+	if parallelism < 1 {
+		parallelism = DefaultParallelism
+	}
+	var (
+		mu      sync.Mutex
+		results = map[string]T{}
+		errs    = map[string]error{}
+	)
+	err := parallel.ForEach(ctx, parallelism, r.Tenants(), func(_ int, tenant string) {
+		c, err := r.Client(tenant)
+		if err != nil {
+			// removed concurrently
+			return
+		}
+		v, err := fn(ctx, tenant, c)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs[tenant] = err
+		} else {
+			results[tenant] = v
+		}
+	})
+	return results, errs, err
+}
+
+// ForEachTenant calls fn with the client of each tenant, running up to parallelism calls
+// concurrently. It returns the errors of the failed calls by tenant; a failed call doesn't stop the
+// others. The returned error is only set if ctx is done.
+func (r *Registry) ForEachTenant(ctx context.Context, parallelism int, fn func(ctx context.Context, tenant string, c *Client) error) (map[string]error, error) {
+	_, errs, err := CollectFromTenants(ctx, r, parallelism, func(ctx context.Context, tenant string, c *Client) (struct{}, error) {
+		return struct{}{}, fn(ctx, tenant, c)
+	})
+	return errs, err
+}
+
+// CountAccounts gets the total count of accounts of each tenant, running up to DefaultParallelism
+// calls concurrently. See CollectFromTenants for the returned values.
+// See https://reseller.api.foxyproxy.com/#_count_accounts.
+func (r *Registry) CountAccounts(ctx context.Context) (map[string]int, map[string]error, error) {
+	return CollectFromTenants(ctx, r, DefaultParallelism, func(ctx context.Context, _ string, c *Client) (int, error) {
+		return c.CountAccountsContext(ctx)
+	})
+}
+
+// GetNodeCount gets the total number of nodes of each tenant, running up to DefaultParallelism
+// calls concurrently. See CollectFromTenants for the returned values.
+// See https://reseller.api.foxyproxy.com/#_get_node_count.
+func (r *Registry) GetNodeCount(ctx context.Context) (map[string]int, map[string]error, error) {
+	return CollectFromTenants(ctx, r, DefaultParallelism, func(ctx context.Context, _ string, c *Client) (int, error) {
+		return c.GetNodeCountContext(ctx)
+	})
+}
+
+// AccountsByUsername gets the accounts with username of each tenant, running up to
+// DefaultParallelism calls concurrently. Tenants without such accounts are included with an empty
+// slice. See CollectFromTenants for the returned values.
+// See https://reseller.api.foxyproxy.com/#_get_accounts_by_username.
+func (r *Registry) AccountsByUsername(ctx context.Context, username string) (map[string][]*Account, map[string]error, error) {
+	return CollectFromTenants(ctx, r, DefaultParallelism, func(ctx context.Context, _ string, c *Client) ([]*Account, error) {
+		accounts := []*Account{}
+		it := c.AccountsByUsername(ctx, username, nil)
+		for it.Next() {
+			accounts = append(accounts, it.Account())
+		}
+		if err := it.Err(); err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		return accounts, nil
+	})
+}
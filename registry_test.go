@@ -0,0 +1,137 @@
+package foxyproxy_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"github.com/jsignanini/foxyproxy-reseller-go/foxyproxytest"
+)
+
+func TestRegistry(t *testing.T) {
+	brandA, brandB := foxyproxytest.NewServer(), foxyproxytest.NewServer()
+	defer brandA.Close()
+	defer brandB.Close()
+	brandA.AddNode(&foxyproxy.Node{Name: "node-1"})
+	brandA.AddAccount(&foxyproxytest.AccountState{NodeName: "node-1", Username: "john", Password: "secret"})
+	brandB.AddNode(&foxyproxy.Node{Name: "node-1"})
+	brandB.AddAccount(&foxyproxytest.AccountState{NodeName: "node-1", Username: "jane", Password: "secret"})
+	brandB.AddAccount(&foxyproxytest.AccountState{NodeName: "node-1", Username: "max", Password: "secret"})
+
+	var (
+		mu    sync.Mutex
+		calls = map[string]int{}
+	)
+	r := foxyproxy.NewRegistry(&foxyproxy.RegistryParams{
+		Middlewares: func(tenant string) []foxyproxy.Middleware {
+			return []foxyproxy.Middleware{func(next foxyproxy.Doer) foxyproxy.Doer {
+				return foxyproxy.DoerFunc(func(ctx context.Context, req *foxyproxy.Request) (*foxyproxy.Response, error) {
+					mu.Lock()
+					calls[tenant]++
+					mu.Unlock()
+					return next.Do(ctx, req)
+				})
+			}}
+		},
+	})
+	for tenant, srv := range map[string]*foxyproxytest.Server{"brand-a": brandA, "brand-b": brandB} {
+		if _, err := r.Add(tenant, srv.Params()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tenants := r.Tenants(); !reflect.DeepEqual(tenants, []string{"brand-a", "brand-b"}) {
+		t.Errorf("unexpected tenants: %v", tenants)
+	}
+
+	counts, errs, err := r.CountAccounts(context.Background())
+	if err != nil || len(errs) != 0 {
+		t.Fatal(err, errs)
+	}
+	if !reflect.DeepEqual(counts, map[string]int{"brand-a": 1, "brand-b": 2}) {
+		t.Errorf("unexpected counts: %v", counts)
+	}
+	if !reflect.DeepEqual(calls, map[string]int{"brand-a": 1, "brand-b": 1}) {
+		t.Errorf("unexpected calls by tenant: %v", calls)
+	}
+
+	ctx := foxyproxy.WithTenant(context.Background(), "brand-b")
+	c, err := r.ClientFromContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := c.UsernameExistsContext(ctx, "jane"); err != nil || !exists {
+		t.Errorf("expected jane to exist on brand-b, got %v, %v", exists, err)
+	}
+	if _, err := r.Client("brand-c"); !errors.Is(err, foxyproxy.ErrUnknownTenant) {
+		t.Errorf("expected error: %v, got %v", foxyproxy.ErrUnknownTenant, err)
+	}
+	if _, err := r.ClientFromContext(context.Background()); !errors.Is(err, foxyproxy.ErrUnknownTenant) {
+		t.Errorf("expected error: %v, got %v", foxyproxy.ErrUnknownTenant, err)
+	}
+}
+
+func TestRegistryIsolation(t *testing.T) {
+	brandA, brandB := foxyproxytest.NewServer(), foxyproxytest.NewServer()
+	defer brandA.Close()
+	defer brandB.Close()
+	brandA.InjectFault(foxyproxytest.Fault{Status: http.StatusTooManyRequests, RetryAfter: "0", Count: 1})
+	brandB.InjectFault(foxyproxytest.Fault{Status: http.StatusInternalServerError})
+
+	r := foxyproxy.NewRegistry(nil)
+	params := brandA.Params()
+	params.RetryPolicy = &foxyproxy.RetryPolicy{MaxAttempts: 2}
+	if _, err := r.Add("brand-a", params); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Add("brand-b", brandB.Params()); err != nil {
+		t.Fatal(err)
+	}
+
+	counts, errs, err := r.GetNodeCount(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := counts["brand-a"]; !ok || len(counts) != 1 {
+		t.Errorf("expected a count for brand-a only, got %v", counts)
+	}
+	if !errors.Is(errs["brand-b"], foxyproxy.ErrServer) || len(errs) != 1 {
+		t.Errorf("expected a server error for brand-b only, got %v", errs)
+	}
+	if brandA.RequestCount() != 2 || brandB.RequestCount() != 1 {
+		t.Errorf("expected brand-a to be retried only, got %d and %d requests", brandA.RequestCount(), brandB.RequestCount())
+	}
+}
+
+func TestNewRegistryFromConfig(t *testing.T) {
+	srv := foxyproxytest.NewServer()
+	defer srv.Close()
+	config := &foxyproxy.Config{Profiles: map[string]*foxyproxy.Profile{
+		"brand-a": {Username: srv.Username, Password: srv.Password, Domain: srv.Domain, Endpoint: srv.URL},
+		"brand-b": {Username: srv.Username, Domain: srv.Domain, Endpoint: srv.URL},
+	}}
+	t.Setenv("FOXYPROXY_BRAND_B_PASSWORD", srv.Password)
+	r, err := foxyproxy.NewRegistryFromConfig(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tenants := r.Tenants(); !reflect.DeepEqual(tenants, []string{"brand-a", "brand-b"}) {
+		t.Errorf("unexpected tenants: %v", tenants)
+	}
+	errs, err := r.ForEachTenant(context.Background(), 1, func(ctx context.Context, tenant string, c *foxyproxy.Client) error {
+		_, err := c.GetNodeCountContext(ctx)
+		return err
+	})
+	if err != nil || len(errs) != 0 {
+		t.Error(err, errs)
+	}
+
+	delete(config.Profiles, "brand-b")
+	config.Profiles["brand-c"] = &foxyproxy.Profile{Endpoint: srv.URL}
+	if _, err := foxyproxy.NewRegistryFromConfig(config, nil); !errors.Is(err, foxyproxy.ErrValidation) {
+		t.Errorf("expected error: %v, got %v", foxyproxy.ErrValidation, err)
+	}
+}
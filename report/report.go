@@ -0,0 +1,171 @@
+// Package report aggregates the traffic of a reseller pool. Generate collects the traffic by account
+// of every node for a time range and totals it per account, per node and per location:
+//
+//	r, err := report.Generate(ctx, client, start, end, nil)
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println(r.ByUsername["john"].TrafficAll)
+//
+// GenerateSeries splits a time range into hourly, daily, weekly or monthly buckets and returns the
+// traffic of every node and account for each bucket.
+package report
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+)
+
+// Totals is an aggregated traffic count.
+type Totals struct {
+	TrafficDown foxyproxy.ByteSize
+	TrafficUp   foxyproxy.ByteSize
+	TrafficAll  foxyproxy.ByteSize
+}
+
+// Add adds the traffic of an account to the totals.
+func (t *Totals) Add(traffic *foxyproxy.NodeTrafficAccount) {
+	t.TrafficDown = t.TrafficDown.Add(traffic.TrafficDown)
+	t.TrafficUp = t.TrafficUp.Add(traffic.TrafficUp)
+	t.TrafficAll = t.TrafficAll.Add(traffic.TrafficAll)
+}
+
+// AccountTotals is the traffic of an account across nodes.
+type AccountTotals struct {
+	Totals
+	// Username and UID identify the account. When aggregating by username, UID is the one of the
+	// first row added.
+	Username string
+	UID      string
+	// ByNode holds the traffic of the account on each node, by node name.
+	ByNode map[string]*Totals
+}
+
+// NodeTotals is the traffic of a node.
+type NodeTotals struct {
+	Totals
+	Node *foxyproxy.Node
+	// Accounts is the number of accounts with traffic rows on the node.
+	Accounts int
+}
+
+// Location is the location of a node.
+type Location struct {
+	Country string
+	City    string
+}
+
+// Report is the traffic of a reseller pool between StartTime and EndTime, inclusive.
+type Report struct {
+	StartTime, EndTime time.Time
+	// Total is the traffic of all nodes.
+	Total Totals
+	// ByUsername holds the traffic of each username across nodes.
+	ByUsername map[string]*AccountTotals
+	// ByUID holds the traffic of each account UID across nodes.
+	ByUID map[string]*AccountTotals
+	// ByNode holds the traffic of each node, by node name.
+	ByNode map[string]*NodeTotals
+	// ByCountry holds the traffic of the nodes of each country.
+	ByCountry map[string]*Totals
+	// ByLocation holds the traffic of the nodes of each city.
+	ByLocation map[Location]*Totals
+	// Errors holds the errors of the nodes whose traffic could not be collected, by node name.
+	// Their traffic is missing from the report.
+	Errors map[string]error
+
+	mu sync.Mutex
+}
+
+// Params is an object of optional report parameters.
+type Params struct {
+	// Parallelism is the number of nodes queried concurrently. Defaults to
+	// foxyproxy.DefaultParallelism.
+	Parallelism int
+}
+
+// New returns an empty report for the specified time range.
+func New(startTime, endTime time.Time) *Report {
+	return &Report{
+		StartTime:  startTime,
+		EndTime:    endTime,
+		ByUsername: map[string]*AccountTotals{},
+		ByUID:      map[string]*AccountTotals{},
+		ByNode:     map[string]*NodeTotals{},
+		ByCountry:  map[string]*Totals{},
+		ByLocation: map[Location]*Totals{},
+		Errors:     map[string]error{},
+	}
+}
+
+// Generate collects the traffic by account of all nodes retrieved through api between startTime
+// and endTime, inclusive, and aggregates it. Nodes which fail are recorded in Report.Errors; the
+// returned error is only set if the nodes could not be listed or ctx is done. params may be nil.
+// See https://reseller.api.foxyproxy.com/#_node_traffic_by_account.
+func Generate(ctx context.Context, api foxyproxy.API, startTime, endTime time.Time, params *Params) (*Report, error) {
+	if params == nil {
+		params = &Params{}
+	}
+	r := New(startTime, endTime)
+	_, errs, err := foxyproxy.CollectFromNodes(ctx, api, params.Parallelism, func(ctx context.Context, node *foxyproxy.Node) (struct{}, error) {
+		traffics, err := node.GetTrafficByAccountContext(ctx, startTime, endTime)
+		if err == nil {
+			r.Add(node, traffics)
+		}
+		return struct{}{}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.Errors = errs
+	return r, nil
+}
+
+// Add aggregates the traffic by account of node into the report. It is safe for concurrent use.
+func (r *Report) Add(node *foxyproxy.Node, traffics []*foxyproxy.NodeTrafficAccount) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	nt := r.ByNode[node.Name]
+	if nt == nil {
+		nt = &NodeTotals{Node: node}
+		r.ByNode[node.Name] = nt
+	}
+	location := Location{Country: node.Country, City: node.City}
+	for _, traffic := range traffics {
+		r.Total.Add(traffic)
+		nt.Add(traffic)
+		nt.Accounts++
+		addAccount(r.ByUsername, traffic.Username, node.Name, traffic)
+		if traffic.UID != "" {
+			addAccount(r.ByUID, traffic.UID, node.Name, traffic)
+		}
+		totals(r.ByCountry, node.Country).Add(traffic)
+		totals(r.ByLocation, location).Add(traffic)
+	}
+}
+
+func addAccount(accounts map[string]*AccountTotals, key, nodeName string, traffic *foxyproxy.NodeTrafficAccount) {
+	at := accounts[key]
+	if at == nil {
+		at = &AccountTotals{
+			Username: traffic.Username,
+			UID:      traffic.UID,
+			ByNode:   map[string]*Totals{},
+		}
+		accounts[key] = at
+	}
+	at.Add(traffic)
+	totals(at.ByNode, nodeName).Add(traffic)
+}
+
+func totals[K comparable](m map[K]*Totals, key K) *Totals {
+	t := m[key]
+	if t == nil {
+		t = &Totals{}
+		m[key] = t
+	}
+	return t
+}
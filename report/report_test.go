@@ -0,0 +1,62 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"github.com/jsignanini/foxyproxy-reseller-go/foxyproxytest"
+)
+
+func TestGenerate(t *testing.T) {
+	srv := foxyproxytest.NewServer()
+	defer srv.Close()
+	srv.AddNode(&foxyproxy.Node{Name: "node-a", Country: "France", City: "Paris"})
+	srv.AddNode(&foxyproxy.Node{Name: "node-b", Country: "France", City: "Lyon"})
+	srv.AddNode(&foxyproxy.Node{Name: "node-c", Country: "Germany", City: "Berlin"})
+	srv.AddNode(&foxyproxy.Node{Name: "node-d", Country: "Germany", City: "Berlin"})
+	for _, a := range []*foxyproxytest.AccountState{
+		{NodeName: "node-a", Username: "john"},
+		{NodeName: "node-b", Username: "john"},
+		{NodeName: "node-b", Username: "jane"},
+		{NodeName: "node-c", Username: "jane"},
+	} {
+		srv.AddAccount(a)
+	}
+	at := time.Unix(1000, 0)
+	srv.AddTraffic("node-a", "john", at, 1, 10)
+	srv.AddTraffic("node-b", "john", at, 2, 20)
+	srv.AddTraffic("node-b", "jane", at, 4, 40)
+	srv.AddTraffic("node-c", "jane", at, 8, 80)
+	srv.InjectFault(foxyproxytest.Fault{PathPrefix: "/nodes/node-d/", Status: http.StatusInternalServerError})
+
+	r, err := Generate(context.Background(), srv.Client(), at, at, &Params{Parallelism: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Total != (Totals{TrafficUp: 15, TrafficDown: 150, TrafficAll: 165}) {
+		t.Errorf("unexpected total: %+v", r.Total)
+	}
+	john := r.ByUsername["john"]
+	if john == nil || john.TrafficAll != 33 || john.ByNode["node-b"].TrafficAll != 22 {
+		t.Errorf("unexpected traffic of john: %+v", john)
+	}
+	if len(r.ByUID) != 4 {
+		t.Errorf("expected accounts by uid: %d, got %d", 4, len(r.ByUID))
+	}
+	if nt := r.ByNode["node-b"]; nt == nil || nt.TrafficAll != 66 || nt.Accounts != 2 || nt.Node.City != "Lyon" {
+		t.Errorf("unexpected traffic of node-b: %+v", nt)
+	}
+	if r.ByCountry["France"].TrafficAll != 77 || r.ByCountry["Germany"].TrafficAll != 88 {
+		t.Errorf("unexpected traffic by country: %+v, %+v", r.ByCountry["France"], r.ByCountry["Germany"])
+	}
+	if r.ByLocation[Location{Country: "France", City: "Paris"}].TrafficAll != 11 {
+		t.Errorf("unexpected traffic of Paris: %+v", r.ByLocation[Location{Country: "France", City: "Paris"}])
+	}
+	if len(r.Errors) != 1 || !errors.Is(r.Errors["node-d"], foxyproxy.ErrServer) {
+		t.Errorf("expected error of node-d, got %v", r.Errors)
+	}
+}
@@ -0,0 +1,243 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"github.com/jsignanini/foxyproxy-reseller-go/internal/parallel"
+)
+
+// Interval is the duration of the buckets of a series.
+type Interval int
+
+// Supported intervals. Buckets are aligned to the start of the hour, day, week (Monday) or month
+// in the location of the series.
+const (
+	Hour Interval = iota + 1
+	Day
+	Week
+	Month
+)
+
+// String returns the name of the interval.
+func (i Interval) String() string {
+	switch i {
+	case Hour:
+		return "hour"
+	case Day:
+		return "day"
+	case Week:
+		return "week"
+	case Month:
+		return "month"
+	default:
+		return fmt.Sprintf("Interval(%d)", int(i))
+	}
+}
+
+// start returns the start of the interval containing t, in the location of t.
+func (i Interval) start(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch i {
+	case Hour:
+		return t.Add(-time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	case Week:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+	case Month:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// next returns the start of the interval following the one starting at t.
+func (i Interval) next(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch i {
+	case Hour:
+		return t.Add(time.Hour)
+	case Week:
+		return time.Date(y, m, d+7, 0, 0, 0, 0, t.Location())
+	case Month:
+		return time.Date(y, m+1, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// Bucket is a time range of a series, from Start to End inclusive, at the one second resolution of
+// the api.
+type Bucket struct {
+	Start, End time.Time
+}
+
+// Buckets splits the time range from startTime to endTime, inclusive, into consecutive buckets
+// aligned to interval in loc. The first and last buckets are clipped to the time range. If loc is
+// nil, the location of startTime is used.
+func Buckets(startTime, endTime time.Time, interval Interval, loc *time.Location) []Bucket {
+	if loc == nil {
+		loc = startTime.Location()
+	}
+	if interval < Hour || interval > Month {
+		interval = Day
+	}
+	buckets := []Bucket{}
+	start := startTime.In(loc).Truncate(time.Second)
+	endTime = endTime.In(loc)
+	for !start.After(endTime) {
+		next := interval.next(interval.start(start))
+		end := next.Add(-time.Second)
+		if end.After(endTime) {
+			end = endTime
+		}
+		buckets = append(buckets, Bucket{Start: start, End: end})
+		start = next
+	}
+	return buckets
+}
+
+// Point is the traffic of a bucket of a series. Exactly one of Totals and Err is set, unless the
+// point is a gap.
+type Point struct {
+	Bucket
+	// Totals is the traffic of the bucket. It is nil if the traffic could not be retrieved or the
+	// account had no traffic row for the bucket.
+	Totals *Totals
+	// Err is the error of the request of the bucket, if it failed.
+	Err error
+}
+
+// Gap reports whether there is no data for the point, without an error.
+func (p *Point) Gap() bool {
+	return p.Totals == nil && p.Err == nil
+}
+
+// AccountKey identifies an account of a series.
+type AccountKey struct {
+	NodeName string
+	Username string
+}
+
+// Series is the traffic of a reseller pool split into buckets.
+type Series struct {
+	Interval Interval
+	Buckets  []Bucket
+	// ByNode holds the traffic totals of each node, with one point per bucket, by node name.
+	ByNode map[string][]Point
+	// ByAccount holds the traffic of each account with at least one traffic row in the time range,
+	// with one point per bucket. Buckets in which the account had no row are gaps; buckets in which
+	// the traffic by account of the node could not be retrieved hold the error.
+	ByAccount map[AccountKey][]Point
+}
+
+// SeriesParams is an object of optional series parameters.
+type SeriesParams struct {
+	// Location is the time zone the buckets are aligned in. Defaults to the location of the start
+	// time.
+	Location *time.Location
+	// Parallelism is the number of concurrent requests. Defaults to
+	// foxyproxy.DefaultParallelism.
+	Parallelism int
+}
+
+// GenerateSeries splits the time range from startTime to endTime, inclusive, into buckets of the
+// specified interval and gets the traffic totals and the traffic by account of every node retrieved
+// through api for each bucket, concurrently. Failed requests are recorded in the points of the
+// series; the returned error is only set if the nodes could not be listed or ctx is done. params
+// may be nil.
+// See https://reseller.api.foxyproxy.com/#_node_traffic_totals and
+// https://reseller.api.foxyproxy.com/#_node_traffic_by_account.
+func GenerateSeries(ctx context.Context, api foxyproxy.API, startTime, endTime time.Time, interval Interval, params *SeriesParams) (*Series, error) {
+	if params == nil {
+		params = &SeriesParams{}
+	}
+	parallelism := params.Parallelism
+	if parallelism < 1 {
+		parallelism = foxyproxy.DefaultParallelism
+	}
+	nodes := []*foxyproxy.Node{}
+	it := api.Nodes(ctx, nil)
+	for it.Next() {
+		nodes = append(nodes, it.Node())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	s := &Series{
+		Interval:  interval,
+		Buckets:   Buckets(startTime, endTime, interval, params.Location),
+		ByNode:    map[string][]Point{},
+		ByAccount: map[AccountKey][]Point{},
+	}
+	type accountsPoint struct {
+		traffics []*foxyproxy.NodeTrafficAccount
+		err      error
+	}
+	accounts := make([][]accountsPoint, len(nodes))
+	tasks := []func(){}
+	for n, node := range nodes {
+		n, node := n, node
+		points := s.newPoints()
+		s.ByNode[node.Name] = points
+		accounts[n] = make([]accountsPoint, len(s.Buckets))
+		for i, b := range s.Buckets {
+			i, b := i, b
+			tasks = append(tasks, func() {
+				totals, err := node.GetTrafficTotalsContext(ctx, b.Start, b.End)
+				if err != nil {
+					points[i].Err = err
+					return
+				}
+				points[i].Totals = &Totals{TrafficDown: totals.TrafficDown, TrafficUp: totals.TrafficUp, TrafficAll: totals.TrafficAll}
+			}, func() {
+				traffics, err := node.GetTrafficByAccountContext(ctx, b.Start, b.End)
+				accounts[n][i] = accountsPoint{traffics: traffics, err: err}
+			})
+		}
+	}
+	if err := parallel.ForEach(ctx, parallelism, tasks, func(_ int, task func()) { task() }); err != nil {
+		return nil, err
+	}
+
+	for n, node := range nodes {
+		for i, ap := range accounts[n] {
+			for _, traffic := range ap.traffics {
+				key := AccountKey{NodeName: node.Name, Username: traffic.Username}
+				points, ok := s.ByAccount[key]
+				if !ok {
+					points = s.newPoints()
+					s.ByAccount[key] = points
+				}
+				if points[i].Totals == nil {
+					points[i].Totals = &Totals{}
+				}
+				points[i].Totals.Add(traffic)
+			}
+		}
+		// mark the failed buckets of every account of the node
+		for i, ap := range accounts[n] {
+			if ap.err == nil {
+				continue
+			}
+			for key, points := range s.ByAccount {
+				if key.NodeName == node.Name {
+					points[i].Err = ap.err
+				}
+			}
+		}
+	}
+	return s, nil
+}
+
+// newPoints returns a point for each bucket of the series.
+func (s *Series) newPoints() []Point {
+	points := make([]Point, len(s.Buckets))
+	for i, b := range s.Buckets {
+		points[i].Bucket = b
+	}
+	return points
+}
@@ -0,0 +1,110 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	foxyproxy "github.com/jsignanini/foxyproxy-reseller-go"
+	"github.com/jsignanini/foxyproxy-reseller-go/foxyproxytest"
+)
+
+func TestBuckets(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		interval   Interval
+		start, end time.Time
+		expected   []string
+	}{
+		{
+			Hour,
+			time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+			time.Date(2024, 3, 1, 12, 15, 0, 0, time.UTC),
+			[]string{"10:30:00-10:59:59", "11:00:00-11:59:59", "12:00:00-12:15:00"},
+		},
+		{
+			// the day of the switch to summer time lasts 23 hours
+			Day,
+			time.Date(2024, 3, 30, 0, 0, 0, 0, paris),
+			time.Date(2024, 3, 31, 23, 59, 59, 0, paris),
+			[]string{"2024-03-30T00:00:00+01:00/2024-03-30T23:59:59+01:00", "2024-03-31T00:00:00+01:00/2024-03-31T23:59:59+02:00"},
+		},
+		{
+			Week,
+			time.Date(2024, 3, 6, 12, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC),
+			[]string{"2024-03-06T12:00:00Z/2024-03-10T23:59:59Z", "2024-03-11T00:00:00Z/2024-03-12T00:00:00Z"},
+		},
+		{
+			Month,
+			time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			[]string{"2024-01-15T00:00:00Z/2024-01-31T23:59:59Z", "2024-02-01T00:00:00Z/2024-02-29T23:59:59Z", "2024-03-01T00:00:00Z/2024-03-01T00:00:00Z"},
+		},
+	}
+	for _, tt := range tests {
+		buckets := Buckets(tt.start, tt.end, tt.interval, nil)
+		actual := []string{}
+		for _, b := range buckets {
+			if tt.interval == Hour {
+				actual = append(actual, b.Start.Format("15:04:05")+"-"+b.End.Format("15:04:05"))
+			} else {
+				actual = append(actual, b.Start.Format(time.RFC3339)+"/"+b.End.Format(time.RFC3339))
+			}
+		}
+		if fmt.Sprint(actual) != fmt.Sprint(tt.expected) {
+			t.Errorf("%s: expected buckets: %v, got %v", tt.interval, tt.expected, actual)
+		}
+	}
+}
+
+func TestGenerateSeries(t *testing.T) {
+	srv := foxyproxytest.NewServer()
+	defer srv.Close()
+	srv.AddNode(&foxyproxy.Node{Name: "node-a"})
+	srv.AddNode(&foxyproxy.Node{Name: "node-b"})
+	srv.AddAccount(&foxyproxytest.AccountState{NodeName: "node-a", Username: "john"})
+	srv.AddAccount(&foxyproxytest.AccountState{NodeName: "node-a", Username: "jane"})
+	srv.AddAccount(&foxyproxytest.AccountState{NodeName: "node-b", Username: "max"})
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	srv.AddTraffic("node-a", "john", start.Add(30*time.Minute), 1, 10)
+	srv.AddTraffic("node-a", "john", start.Add(150*time.Minute), 2, 20)
+	srv.AddTraffic("node-a", "jane", start.Add(90*time.Minute), 4, 40)
+	// the second hour of node-b fails
+	hour := start.Add(time.Hour).Unix()
+	srv.InjectFault(foxyproxytest.Fault{PathPrefix: fmt.Sprintf("/nodes/node-b/traffic/%d/", hour), Status: http.StatusInternalServerError})
+	srv.InjectFault(foxyproxytest.Fault{PathPrefix: fmt.Sprintf("/nodes/node-b/traffic-by-account/%d/", hour), Status: http.StatusInternalServerError})
+
+	s, err := GenerateSeries(context.Background(), srv.Client(), start, start.Add(3*time.Hour-time.Second), Hour, &SeriesParams{Parallelism: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Buckets) != 3 {
+		t.Fatalf("expected buckets: %d, got %d", 3, len(s.Buckets))
+	}
+	nodeA := s.ByNode["node-a"]
+	if nodeA[0].Totals.TrafficAll != 11 || nodeA[1].Totals.TrafficAll != 44 || nodeA[2].Totals.TrafficAll != 22 {
+		t.Errorf("unexpected series of node-a: %+v", nodeA)
+	}
+	nodeB := s.ByNode["node-b"]
+	if !errors.Is(nodeB[1].Err, foxyproxy.ErrServer) || nodeB[0].Totals == nil || nodeB[0].Totals.TrafficAll != 0 {
+		t.Errorf("unexpected series of node-b: %+v", nodeB)
+	}
+	john := s.ByAccount[AccountKey{NodeName: "node-a", Username: "john"}]
+	if john[0].Totals.TrafficAll != 11 || john[1].Totals.TrafficAll != 0 || john[2].Totals.TrafficAll != 22 {
+		t.Errorf("unexpected series of john: %+v", john)
+	}
+	maxPoints := s.ByAccount[AccountKey{NodeName: "node-b", Username: "max"}]
+	if maxPoints[0].Totals == nil || !errors.Is(maxPoints[1].Err, foxyproxy.ErrServer) || maxPoints[1].Gap() {
+		t.Errorf("unexpected series of max: %+v", maxPoints)
+	}
+	if len(s.ByAccount) != 3 {
+		t.Errorf("expected accounts: %d, got %d", 3, len(s.ByAccount))
+	}
+}
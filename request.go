@@ -2,32 +2,144 @@ package foxyproxy
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"time"
 )
 
-func (c *Client) doRequest(method, path string, body []byte) (*http.Response, error) {
-	client := http.Client{}
-	req, err := http.NewRequest(method, fmt.Sprintf("%s%s", c.endpointBaseURL, path), bytes.NewReader(body))
+// doJSON performs req with the specified body through the client's middleware chain and decodes
+// the JSON response body into v. If v is nil, the response body is ignored.
+func (c *Client) doJSON(ctx context.Context, req *Request, body []byte, v interface{}) error {
+	req.Body = body
+	req.Result = v
+	_, err := c.doer.Do(ctx, req)
+	return err
+}
+
+// send is the innermost Doer of the middleware chain. It performs req and decodes the response
+// body into req.Result.
+func (c *Client) send(ctx context.Context, req *Request) (*Response, error) {
+	res, err := c.doRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
-	req.SetBasicAuth(c.username, c.password)
-	req.Header.Add("Accept", ContentType)
-	req.Header.Add("Content-Type", ContentType)
-	req.Header.Add("X-DOMAIN", c.domainHeader)
-	res, err := client.Do(req)
+	bodyBytes, err := ioutil.ReadAll(res.Body)
+	if err == nil && req.Result != nil {
+		err = json.Unmarshal(bodyBytes, req.Result)
+	}
+	if err != nil {
+		return nil, &RequestError{Method: req.Method, Path: req.Path, Err: err}
+	}
+	return &Response{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       bodyBytes,
+	}, nil
+}
+
+// doRequest performs a request, retrying it according to the client's retry policy, and once more
+// if it was rejected with 401 Unauthorized and the refreshed credentials differ. Responses with an
+// unexpected status are returned as *Error, other failures as *RequestError.
+func (c *Client) doRequest(ctx context.Context, req *Request) (*http.Response, error) {
+	refreshed := false
+	for attempt := 1; ; attempt++ {
+		if err := c.limiter(req.Method).wait(ctx); err != nil {
+			return nil, &RequestError{Method: req.Method, Path: req.Path, Err: err}
+		}
+		creds, err := c.credentials.Credentials(ctx)
+		if err != nil {
+			return nil, &RequestError{Method: req.Method, Path: req.Path, Err: err}
+		}
+		start := time.Now()
+		res, err := c.doAttempt(ctx, req, creds)
+		if err == nil && res.StatusCode == http.StatusUnauthorized && !refreshed {
+			refreshed = true
+			changed, refreshErr := c.refreshCredentials(ctx, creds)
+			if changed {
+				c.logAttempt(ctx, req, attempt, time.Since(start), res, err, true, 0)
+				continue
+			}
+			if refreshErr != nil {
+				c.logAttempt(ctx, req, attempt, time.Since(start), res, refreshErr, false, 0)
+				_, apiErr := checkResponse(res)
+				return nil, &RequestError{Method: req.Method, Path: req.Path, Err: fmt.Errorf("%w (refreshing the credentials: %w)", apiErr, refreshErr)}
+			}
+		}
+		wait, retry := c.retryPolicy.retry(ctx, req.Method, attempt, res, err)
+		c.logAttempt(ctx, req, attempt, time.Since(start), res, err, retry, wait)
+		if !retry {
+			if err != nil {
+				return nil, &RequestError{Method: req.Method, Path: req.Path, Err: err}
+			}
+			return checkResponse(res)
+		}
+		if c.retryPolicy.OnRetry != nil {
+			ra := &RetryAttempt{
+				Method:  req.Method,
+				Path:    req.Path,
+				Attempt: attempt,
+				Err:     err,
+				Wait:    wait,
+			}
+			if res != nil {
+				ra.StatusCode = res.StatusCode
+			}
+			c.retryPolicy.OnRetry(ra)
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, &RequestError{Method: req.Method, Path: req.Path, Err: err}
+		}
+	}
+}
+
+// refreshCredentials refreshes the client's credentials after old were rejected, and reports
+// whether they changed.
+func (c *Client) refreshCredentials(ctx context.Context, old Credentials) (bool, error) {
+	if err := c.credentials.Refresh(ctx); err != nil {
+		return false, err
+	}
+	creds, err := c.credentials.Credentials(ctx)
+	if err != nil {
+		return false, err
+	}
+	return creds != old, nil
+}
+
+// doAttempt performs a single request with creds. The returned response body is fully read so the
+// underlying connection can be reused.
+func (c *Client) doAttempt(ctx context.Context, req *Request, creds Credentials) (*http.Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, fmt.Sprintf("%s%s", c.endpointBaseURL, req.Path), bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.SetBasicAuth(creds.Username, creds.Password)
+	httpReq.Header.Add("Accept", ContentType)
+	httpReq.Header.Add("Content-Type", ContentType)
+	httpReq.Header.Add("X-DOMAIN", c.domainHeader)
+	for key, values := range req.Header {
+		httpReq.Header[key] = values
+	}
+	res, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	bodyBytes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, err
 	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
+	return res, nil
+}
+
+func checkResponse(res *http.Response) (*http.Response, error) {
 	switch res.StatusCode {
-	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+	case http.StatusOK, http.StatusNoContent:
 		return res, nil
 	default:
-		apiError, err := NewError(res.Body)
-		if err != nil {
-			return nil, err
-		}
-		return nil, apiError
+		return nil, newResponseError(res)
 	}
 }
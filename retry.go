@@ -0,0 +1,145 @@
+package foxyproxy
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryMethods are the HTTP methods retried when RetryPolicy.Methods is empty. Only
+// idempotent methods are included; add http.MethodPatch and http.MethodDelete to
+// RetryPolicy.Methods to opt in to retrying account updates and deletions.
+var DefaultRetryMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut}
+
+// DefaultRetryPolicy is a reasonable retry policy for most callers.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+}
+
+// RetryPolicy configures how requests are retried when the api responds with 429 Too Many
+// Requests or a 5xx status, or when the request fails to reach the api.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one. Values lower than 2
+	// disable retries.
+	MaxAttempts int
+	// MinBackoff is the wait before the first retry. It doubles on every subsequent retry, up to
+	// MaxBackoff, and a random jitter of up to half its value is subtracted.
+	MinBackoff time.Duration
+	// MaxBackoff caps the exponential backoff. A Retry-After header sent by the api replaces the
+	// backoff; if it asks for a longer wait than MaxBackoff, the request is not retried and its
+	// error is returned. Zero means no cap.
+	MaxBackoff time.Duration
+	// Methods lists the HTTP methods which are retried. Defaults to DefaultRetryMethods.
+	Methods []string
+	// OnRetry, if set, is called before waiting for every retry.
+	OnRetry func(*RetryAttempt)
+}
+
+// RetryAttempt describes a failed attempt which is about to be retried.
+type RetryAttempt struct {
+	Method string
+	Path   string
+	// Attempt is the one-based number of the failed attempt.
+	Attempt int
+	// StatusCode is the response status of the failed attempt, or 0 if no response was received.
+	StatusCode int
+	// Err is the transport error of the failed attempt, if any.
+	Err error
+	// Wait is how long the client waits before the next attempt.
+	Wait time.Duration
+}
+
+func (p *RetryPolicy) allowsMethod(method string) bool {
+	methods := p.Methods
+	if len(methods) == 0 {
+		methods = DefaultRetryMethods
+	}
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the wait before retrying after the specified failed attempt.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.MinBackoff
+	for i := 1; i < attempt; i++ {
+		wait *= 2
+		if p.MaxBackoff > 0 && wait >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if half := int64(wait / 2); half > 0 {
+		wait -= time.Duration(rand.Int63n(half))
+	}
+	return wait
+}
+
+// retry reports whether a request which ended with res or err should be attempted again, and how
+// long to wait before doing so.
+func (p *RetryPolicy) retry(ctx context.Context, method string, attempt int, res *http.Response, err error) (time.Duration, bool) {
+	if p == nil || attempt >= p.MaxAttempts || !p.allowsMethod(method) || ctx.Err() != nil {
+		return 0, false
+	}
+	if err == nil && !retryableStatus(res.StatusCode) {
+		return 0, false
+	}
+	wait := p.backoff(attempt)
+	if res != nil {
+		if retryAfter, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			if p.MaxBackoff > 0 && retryAfter > p.MaxBackoff {
+				return 0, false
+			}
+			wait = retryAfter
+		}
+	}
+	return wait, true
+}
+
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || (status >= 500 && status != http.StatusNotImplemented)
+}
+
+// parseRetryAfter parses a Retry-After header value, which is either a number of seconds or an
+// HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	wait := time.Until(date)
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
+
+// sleep waits for d or until ctx is done, whichever happens first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
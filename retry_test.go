@@ -0,0 +1,148 @@
+package foxyproxy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newFlakyServer(failures int32, status int) (*httptest.Server, *int32) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			w.Write([]byte(`{"status":503,"error":"Service Unavailable"}`))
+			return
+		}
+		w.Write([]byte(`{"count":3}`))
+	}))
+	return ts, &calls
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	ts, calls := newFlakyServer(2, http.StatusServiceUnavailable)
+	defer ts.Close()
+	attempts := []int{}
+	c := newTestClient(t, &NewClientParams{
+		EndpointBaseURL: ts.URL,
+		RetryPolicy: &RetryPolicy{
+			MaxAttempts: 3,
+			MinBackoff:  time.Millisecond,
+			OnRetry: func(ra *RetryAttempt) {
+				attempts = append(attempts, ra.Attempt)
+				if ra.StatusCode != http.StatusServiceUnavailable {
+					t.Errorf("expected retry status: %d, got %d", http.StatusServiceUnavailable, ra.StatusCode)
+				}
+			},
+		},
+	})
+	count, err := c.CountAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("expected count: %d, got %d", 3, count)
+	}
+	if *calls != 3 {
+		t.Errorf("expected calls: %d, got %d", 3, *calls)
+	}
+	if len(attempts) != 2 {
+		t.Errorf("expected retry hook calls: %d, got %d", 2, len(attempts))
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	ts, calls := newFlakyServer(5, http.StatusTooManyRequests)
+	defer ts.Close()
+	c := newTestClient(t, &NewClientParams{
+		EndpointBaseURL: ts.URL,
+		RetryPolicy: &RetryPolicy{
+			MaxAttempts: 2,
+			MinBackoff:  time.Millisecond,
+		},
+	})
+	if _, err := c.CountAccounts(); err == nil {
+		t.Error("expected error, got nil")
+	}
+	if *calls != 2 {
+		t.Errorf("expected calls: %d, got %d", 2, *calls)
+	}
+}
+
+func TestRetryAfterExceedsMaxBackoff(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+	c := newTestClient(t, &NewClientParams{
+		EndpointBaseURL: ts.URL,
+		RetryPolicy: &RetryPolicy{
+			MaxAttempts: 3,
+			MinBackoff:  time.Millisecond,
+			MaxBackoff:  time.Second,
+		},
+	})
+	start := time.Now()
+	if _, err := c.CountAccounts(); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected error: %v, got %v", ErrRateLimited, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected no wait, waited %v", elapsed)
+	}
+	if calls != 1 {
+		t.Errorf("expected calls: %d, got %d", 1, calls)
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	tests := []struct {
+		methods []string
+		calls   int32
+	}{
+		{nil, 1},
+		{[]string{http.MethodPatch}, 2},
+	}
+	for _, test := range tests {
+		ts, calls := newFlakyServer(1, http.StatusBadGateway)
+		c := newTestClient(t, &NewClientParams{
+			EndpointBaseURL: ts.URL,
+			RetryPolicy: &RetryPolicy{
+				MaxAttempts: 3,
+				MinBackoff:  time.Millisecond,
+				Methods:     test.methods,
+			},
+		})
+		c.ActivateAccount("john")
+		if *calls != test.calls {
+			t.Errorf("methods %v: expected calls: %d, got %d", test.methods, test.calls, *calls)
+		}
+		ts.Close()
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		wait  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"-1", 0, false},
+		{"Wed, 21 Oct 2015 07:28:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+	for _, test := range tests {
+		wait, ok := parseRetryAfter(test.value)
+		if wait != test.wait || ok != test.ok {
+			t.Errorf("%q: expected (%s, %t), got (%s, %t)", test.value, test.wait, test.ok, wait, ok)
+		}
+	}
+}